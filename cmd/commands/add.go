@@ -9,13 +9,17 @@ import (
 
 var (
 	addRemote string
+	addFetch  bool
 )
 
 var addCmd = &cobra.Command{
 	Use:   "add <branch> [name]",
 	Short: "Add an existing branch as a worktree",
-	Long:  `Creates a new worktree for an existing local or remote branch and navigates to it. Optionally specify a custom directory name.`,
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `Creates a new worktree for an existing local or remote branch and navigates to it. Optionally specify a custom directory name.
+
+Without --remote every remote is searched for the branch. If several have it,
+name the one to use with --remote or as remote/branch.`,
+	Args: cobra.RangeArgs(1, 2),
 	ValidArgsFunction: pkg.RepoCompletion(func(
 		repo *pkg.Repo,
 		cmd *cobra.Command,
@@ -41,14 +45,23 @@ var addCmd = &cobra.Command{
 		return pkg.GlobFilterComplete(args, filtered, toComplete), cobra.ShellCompDirectiveNoFileComp
 	}),
 	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
-		branch := args[0]
+		if addFetch {
+			if err := repo.FetchRemotes(addRemote); err != nil {
+				return err
+			}
+		}
+
+		remote, branch, err := repo.ResolveRemoteBranch(args[0], addRemote)
+		if err != nil {
+			return err
+		}
 		name := branch
 		if len(args) > 1 {
 			name = args[1]
 		}
 
 		// Try to add the existing branch
-		worktree, err := repo.AddExistingBranch(branch, name, addRemote)
+		worktree, err := repo.AddExistingBranch(branch, name, remote)
 		if err != nil {
 			return err
 		}
@@ -65,6 +78,7 @@ var addCmd = &cobra.Command{
 
 // NewAddCmd returns the add command
 func NewAddCmd() *cobra.Command {
-	addCmd.Flags().StringVar(&addRemote, "remote", "origin", "Remote to use for fetching branches")
+	addCmd.Flags().StringVar(&addRemote, "remote", "", "Remote to take the branch from (default: search all remotes)")
+	addCmd.Flags().BoolVar(&addFetch, "fetch", false, "Fetch remote branches before adding")
 	return addCmd
 }
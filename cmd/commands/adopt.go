@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <path|glob>...",
+	Short: "Move worktrees created outside wrk into .{repo}.worktrees",
+	Long: `Moves worktrees made with git worktree add elsewhere into .{repo}.worktrees,
+points their skip symlinks back at the main worktree, and applies skip and
+always-copy settings as for a new worktree. Local changes are kept: existing
+files are not overwritten and modified files are not skipped.
+
+Worktrees are matched by path, directory name or branch. Use
+'wrk list --unmanaged' to find them.`,
+	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		var candidates []string
+		for _, wt := range repo.UnmanagedWorktrees() {
+			candidates = append(candidates, wt.Path, wt.Name)
+		}
+		return pkg.GlobFilterComplete(args, candidates, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		var toAdopt []*pkg.Worktree
+		var adopted []string
+		var errors []string
+
+		seen := make(map[*pkg.Worktree]bool)
+		for _, pattern := range args {
+			matches, err := repo.FindUnmanaged(pattern)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("  %v", err))
+				continue
+			}
+			for _, wt := range matches {
+				if !seen[wt] {
+					seen[wt] = true
+					toAdopt = append(toAdopt, wt)
+				}
+			}
+		}
+
+		adoptedCurrent := false
+		for _, wt := range toAdopt {
+			if err := repo.AdoptWorktree(wt); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %v", wt.Name, err))
+				continue
+			}
+			adopted = append(adopted, wt.Name)
+			if wt == repo.CurrentWorktree {
+				adoptedCurrent = true
+			}
+		}
+
+		if len(adopted) > 0 {
+			fmt.Printf("Adopted %d worktree(s): %s\n", len(adopted), strings.Join(adopted, ", "))
+		}
+
+		// The current directory moved with the worktree
+		if adoptedCurrent {
+			pkg.ChangeDirectory(repo.CurrentWorktree.Path)
+		}
+
+		if len(errors) > 0 {
+			return fmt.Errorf("failed to adopt %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+		}
+		return nil
+	}),
+}
+
+// NewAdoptCmd returns the adopt command
+func NewAdoptCmd() *cobra.Command {
+	return adoptCmd
+}
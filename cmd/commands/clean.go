@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanExpired bool
+	cleanYes     bool
+	cleanDryRun  bool
+	cleanForce   bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean --expired",
+	Short: "Remove worktrees that are no longer needed",
+	Long: `Removes worktrees past their expiry, such as those created by 'wrk review'.
+Lists them and asks before removing unless --yes is given.
+
+Expired worktrees with uncommitted changes, a branch checked out, or commits
+not on any branch are kept unless --force is given.`,
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	Annotations:       map[string]string{pkg.SkipExpiryPrompt: "true"},
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if !cleanExpired {
+			return fmt.Errorf("nothing to clean (use --expired)")
+		}
+
+		expired := repo.ExpiredWorktrees()
+		if len(expired) == 0 {
+			fmt.Println("No expired worktrees.")
+			return nil
+		}
+
+		// Worktrees with work that removing them would lose need --force
+		var removable []*pkg.Worktree
+		for _, wt := range expired {
+			line := fmt.Sprintf("  %s (expired %s)", wt.Name, wt.Meta.Expires.Format("2006-01-02 15:04"))
+			if reason := repo.ExpiryKeepReason(wt); reason != "" {
+				if !cleanForce {
+					fmt.Println(line + color.YellowString(" kept: %s (use --force to remove)", reason))
+					continue
+				}
+				line += ": " + reason
+			}
+			fmt.Println(line)
+			removable = append(removable, wt)
+		}
+
+		if cleanDryRun || len(removable) == 0 {
+			return nil
+		}
+		if !cleanYes && !pkg.Confirm(fmt.Sprintf("Remove %d expired worktree(s)?", len(removable))) {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		var removed []string
+		var errors []string
+		removedCurrent := false
+		for _, wt := range removable {
+			if err := repo.RemoveWorktree(wt, false); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %v", wt.Name, err))
+				continue
+			}
+			removed = append(removed, wt.Name)
+			if wt == repo.CurrentWorktree {
+				removedCurrent = true
+			}
+		}
+
+		if len(removed) > 0 {
+			fmt.Printf("Removed %d worktree(s): %s\n", len(removed), strings.Join(removed, ", "))
+		}
+
+		if len(errors) > 0 {
+			return fmt.Errorf("failed to remove %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+		}
+
+		if removedCurrent {
+			pkg.ChangeDirectory(repo.MainWorktree.Path)
+		}
+		return nil
+	}),
+}
+
+// NewCleanCmd returns the clean command
+func NewCleanCmd() *cobra.Command {
+	cleanCmd.Flags().BoolVar(&cleanExpired, "expired", false, "Remove worktrees past their expiry")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Remove without asking")
+	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "Also remove expired worktrees with work that would be lost")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "List expired worktrees without removing them")
+	return cleanCmd
+}
@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneNoCheckout bool
+	cloneFilter     string
+	cloneBranches   []string
+)
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url> [dir]",
+	Short: "Clone a repository into the worktree layout",
+	Long: `Clones a repository, creates the .{repo}.worktrees directory beside it with a
+starter .config.yml, optionally creates worktrees for some branches, and
+navigates to the clone.
+
+Use --no-checkout to check out only top-level files in the main worktree when
+all work happens in worktrees, and --filter for a partial clone (e.g. --filter
+blob:none). The main worktree uses a sparse checkout, so git status stays
+clean. Skip links and always-copy read files from the main worktree, so run
+'wrk sparse set --full' there before using them for files in directories.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := ""
+		if len(args) > 1 {
+			dir = args[1]
+		}
+
+		repo, worktrees, err := pkg.Clone(args[0], dir, pkg.CloneOptions{
+			NoCheckout: cloneNoCheckout,
+			Filter:     cloneFilter,
+			Branches:   cloneBranches,
+		})
+		if repo == nil {
+			return err
+		}
+
+		fmt.Printf("Cloned into '%s'\n", repo.MainWorktree.Path)
+		for _, wt := range worktrees {
+			fmt.Printf("Worktree created: '%s'\n", wt.Name)
+		}
+		pkg.ChangeDirectory(repo.MainWorktree.Path)
+		return err
+	},
+}
+
+// NewCloneCmd returns the clone command
+func NewCloneCmd() *cobra.Command {
+	cloneCmd.Flags().BoolVar(&cloneNoCheckout, "no-checkout", false, "Only check out top-level files in the main worktree")
+	cloneCmd.Flags().StringVar(&cloneFilter, "filter", "", "Partial clone filter (e.g. blob:none)")
+	cloneCmd.Flags().StringSliceVarP(&cloneBranches, "branch", "b", nil, "Create a worktree for a branch (repeatable or comma-separated)")
+	return cloneCmd
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up an existing clone for wrk",
+	Long: `Creates the .{repo}.worktrees directory with a starter .config.yml and moves
+any worktrees that live elsewhere into it with git worktree move.`,
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		moved, err := repo.Init()
+		if err != nil && len(moved) == 0 {
+			return err
+		}
+
+		movedCurrent := false
+		for _, wt := range moved {
+			fmt.Printf("Moved %s into %s\n", wt.Name, filepath.Dir(wt.Path))
+			if wt == repo.CurrentWorktree {
+				movedCurrent = true
+			}
+		}
+		fmt.Printf("Initialised %s\n", repo.WorktreesDir)
+
+		if movedCurrent {
+			pkg.ChangeDirectory(repo.CurrentWorktree.Path)
+		}
+		return err
+	}),
+}
+
+// NewInitCmd returns the init command
+func NewInitCmd() *cobra.Command {
+	return initCmd
+}
@@ -14,6 +14,12 @@ var (
 	sourceWorktree string
 	alwaysCopy     bool
 	alwaysRemove   bool
+	copyMode       string
+	copyExclude    []string
+	copyGitignore  bool
+	copyOverwrite  bool
+	copySkipExist  bool
+	copyBackup     bool
 )
 
 var copyCmd = &cobra.Command{
@@ -23,7 +29,20 @@ var copyCmd = &cobra.Command{
 
 Use --always to add the path to the config so it's automatically copied to all new worktrees.
 Use --always with no arguments to list all always-copy paths.
-Use --always-rm to remove paths from the always-copy list.`,
+Use --always-rm to remove paths from the always-copy list.
+
+Use --mode to choose how files are copied: reflink (copy-on-write clone on
+btrfs/xfs, the default), hardlink (share data with the source) or copy. Modes
+fall back to a plain copy when not supported. With --always, the mode is
+remembered for that path.
+
+Symlinks are copied as symlinks, and permissions and modification times are
+preserved. Use --exclude to leave out paths matching a glob (repeatable), and
+--gitignore to leave out files git ignores in the source.
+
+If a destination file already exists with different content, nothing is copied
+and the conflicts are listed. Use --overwrite to replace them, --skip-existing
+to keep them, or --backup to move them aside with a .bak suffix first.`,
 	Args: cobra.MaximumNArgs(2),
 	ValidArgsFunction: pkg.RepoCompletion(func(
 		repo *pkg.Repo,
@@ -99,6 +118,15 @@ Use --always-rm to remove paths from the always-copy list.`,
 		return pkg.GlobFilterComplete(args, completions, toComplete), cobra.ShellCompDirectiveNoFileComp
 	}),
 	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		var mode pkg.CopyMode
+		if copyMode != "" {
+			var err error
+			mode, err = pkg.ParseCopyMode(copyMode)
+			if err != nil {
+				return err
+			}
+		}
+
 		// If --always flag is set with no args, list always-copy paths
 		if alwaysCopy && len(args) == 0 {
 			return repo.PrintAlwaysCopy()
@@ -107,7 +135,7 @@ Use --always-rm to remove paths from the always-copy list.`,
 		// If --always flag is set with args, add to config
 		if alwaysCopy {
 			srcPath := args[0]
-			if err := repo.AddAlwaysCopy(srcPath); err != nil {
+			if err := repo.AddAlwaysCopy(srcPath, mode); err != nil {
 				return err
 			}
 			fmt.Printf("Added '%s' to always-copy list\n", srcPath)
@@ -149,7 +177,39 @@ Use --always-rm to remove paths from the always-copy list.`,
 		}
 
 		// Perform the copy
-		return repo.CopyFromWorktree(sourceWt, repo.CurrentWorktree, srcPath, dstPath)
+		opts := pkg.CopyOptions{
+			Mode:      mode,
+			Progress:  pkg.IsTerminal(),
+			Exclude:   copyExclude,
+			Gitignore: copyGitignore,
+			Conflict:  pkg.ConflictFail,
+		}
+		switch {
+		case copyOverwrite:
+			opts.Conflict = pkg.ConflictOverwrite
+		case copySkipExist:
+			opts.Conflict = pkg.ConflictSkip
+		case copyBackup:
+			opts.Conflict = pkg.ConflictBackup
+		}
+
+		result, err := repo.CopyFromWorktree(sourceWt, repo.CurrentWorktree, srcPath, dstPath, opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Copied %d file(s)\n", result.Files)
+		if len(result.Conflicts) > 0 {
+			switch opts.Conflict {
+			case pkg.ConflictOverwrite:
+				fmt.Printf("Overwrote %d existing file(s)\n", len(result.Conflicts))
+			case pkg.ConflictSkip:
+				fmt.Printf("Kept %d existing file(s)\n", len(result.Conflicts))
+			case pkg.ConflictBackup:
+				fmt.Printf("Backed up %d existing file(s) with a .bak suffix\n", len(result.Conflicts))
+			}
+		}
+		return nil
 	}),
 }
 
@@ -158,6 +218,17 @@ func NewCopyCmd() *cobra.Command {
 	copyCmd.Flags().StringVarP(&sourceWorktree, "from", "f", "", "Source worktree (defaults to main worktree)")
 	copyCmd.Flags().BoolVar(&alwaysCopy, "always", false, "Add path to config to automatically copy to new worktrees")
 	copyCmd.Flags().BoolVar(&alwaysRemove, "always-rm", false, "Remove path from always-copy list")
+	copyCmd.Flags().StringVar(&copyMode, "mode", "", "How to copy files: reflink, hardlink or copy")
+	copyCmd.Flags().StringArrayVar(&copyExclude, "exclude", nil, "Skip paths matching a glob (repeatable)")
+	copyCmd.Flags().BoolVar(&copyGitignore, "gitignore", false, "Skip files ignored by git in the source")
+	copyCmd.Flags().BoolVar(&copyOverwrite, "overwrite", false, "Replace existing files that differ")
+	copyCmd.Flags().BoolVar(&copySkipExist, "skip-existing", false, "Keep existing files that differ")
+	copyCmd.Flags().BoolVar(&copyBackup, "backup", false, "Move existing files that differ aside with a .bak suffix")
+	copyCmd.MarkFlagsMutuallyExclusive("overwrite", "skip-existing", "backup")
+	copyCmd.RegisterFlagCompletionFunc("mode", cobra.FixedCompletions(
+		[]string{string(pkg.CopyModeReflink), string(pkg.CopyModeHardlink), string(pkg.CopyModeCopy)},
+		cobra.ShellCompDirectiveNoFileComp,
+	))
 	copyCmd.RegisterFlagCompletionFunc("from", pkg.RepoCompletion(func(
 		repo *pkg.Repo,
 		cmd *cobra.Command,
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFrom      string
+	diffTo        string
+	diffStat      bool
+	diffNameOnly  bool
+	diffGitignore bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [--from <worktree>] [--to <worktree>] [path]",
+	Short: "Compare files between worktrees",
+	Long: `Compare the working directories of two worktrees, including untracked and skipped files that git diff ignores. Paths are relative to the worktree root; with no path, the whole worktree is compared.
+
+By default compares the main worktree with the current one. Use --stat for a
+summary of changed lines or --name-only for just the changed paths, and
+--gitignore to leave out files ignored by git.`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	}),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		from, err := repo.GetSourceWorktree(diffFrom)
+		if err != nil {
+			return err
+		}
+
+		to := repo.CurrentWorktree
+		if diffTo != "" {
+			to, err = repo.FindWorktree(diffTo)
+			if err != nil {
+				return err
+			}
+		}
+
+		if from == to {
+			return fmt.Errorf("nothing to compare, both sides are %s (use --from or --to)", from.Name)
+		}
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		entries, err := repo.DiffWorktrees(from, to, path, diffGitignore)
+		if err != nil {
+			return err
+		}
+
+		format := pkg.DiffUnified
+		if diffStat {
+			format = pkg.DiffStat
+		} else if diffNameOnly {
+			format = pkg.DiffNameOnly
+		}
+
+		return repo.PrintWorktreeDiff(from, to, entries, format)
+	}),
+}
+
+// NewDiffCmd returns the diff command
+func NewDiffCmd() *cobra.Command {
+	diffCmd.Flags().StringVarP(&diffFrom, "from", "f", "", "Worktree to compare from (defaults to main worktree)")
+	diffCmd.Flags().StringVarP(&diffTo, "to", "t", "", "Worktree to compare to (defaults to current worktree)")
+	diffCmd.Flags().BoolVar(&diffStat, "stat", false, "Show a summary of changed lines per file")
+	diffCmd.Flags().BoolVar(&diffNameOnly, "name-only", false, "Show only the names of changed files")
+	diffCmd.Flags().BoolVar(&diffGitignore, "gitignore", false, "Leave out files ignored by git")
+	diffCmd.MarkFlagsMutuallyExclusive("stat", "name-only")
+
+	worktreeCompletion := pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		return pkg.GlobFilterComplete(nil, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	diffCmd.RegisterFlagCompletionFunc("from", worktreeCompletion)
+	diffCmd.RegisterFlagCompletionFunc("to", worktreeCompletion)
+
+	return diffCmd
+}
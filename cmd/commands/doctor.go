@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check for broken worktree state",
+	Long: `Check the repository for state that has drifted over time and explain each problem found. Nothing is changed; use 'repair' to apply fixes.
+
+Checks for:
+  - worktrees whose directories were deleted without 'wrk rm'
+  - directories in the worktrees directory that git doesn't know about
+  - skipped files whose symlink, hardlink or copy no longer matches main
+  - always-copy paths whose source is missing from main
+  - a missing .git/info/exclude file
+  - a skip or unskip that was interrupted part way`,
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		issues, err := repo.Diagnose()
+		if err != nil {
+			return err
+		}
+
+		repo.PrintDiagnosis(issues)
+
+		if len(issues) > 0 {
+			return fmt.Errorf("found %d problem(s), run 'wrk repair' to fix them", len(issues))
+		}
+		return nil
+	}),
+}
+
+// NewDoctorCmd returns the doctor command
+func NewDoctorCmd() *cobra.Command {
+	return doctorCmd
+}
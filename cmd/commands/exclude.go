@@ -10,6 +10,9 @@ import (
 
 var (
 	removeExclude bool
+	localExclude  bool
+	explainIgnore bool
+	auditExclude  bool
 )
 
 var excludeCmd = &cobra.Command{
@@ -17,9 +20,17 @@ var excludeCmd = &cobra.Command{
 	Short: "Manage excluded untracked files",
 	Long: `Manage files that should be excluded from git without modifying .gitignore. Uses .git/info/exclude.
 	
-With no arguments, lists all excluded files.
+With no arguments, lists all excluded files, marking patterns local to the current worktree.
 With file arguments, adds patterns to exclude.
-Use --rm flag to remove exclusions instead.`,
+Use --rm flag to remove exclusions instead.
+Use --local to only affect the current worktree, using a worktree-specific
+exclude file set as its core.excludesFile. This takes the place of any global
+excludes file in that worktree.
+
+Use --explain [path...] to show which source (.gitignore, info/exclude, local
+or global excludes) ignores each path, or every ignored path if none are given.
+Use --audit to list each exclude pattern with the files it matches across all
+worktrees, flagging patterns that match nothing.`,
 	ValidArgsFunction: pkg.RepoCompletion(func(
 		repo *pkg.Repo,
 		cmd *cobra.Command,
@@ -27,7 +38,13 @@ Use --rm flag to remove exclusions instead.`,
 		toComplete string) ([]string, cobra.ShellCompDirective) {
 		// If --rm flag is set, suggest excluded patterns
 		if removeExclude {
-			patterns, err := repo.ListExcludedPatterns()
+			var patterns []string
+			var err error
+			if localExclude {
+				patterns, err = repo.ListLocalExcludedPatterns(repo.CurrentWorktree)
+			} else {
+				patterns, err = repo.ListExcludedPatterns()
+			}
 			if err != nil {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
@@ -37,6 +54,14 @@ Use --rm flag to remove exclusions instead.`,
 		return nil, cobra.ShellCompDirectiveDefault
 	}),
 	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if explainIgnore {
+			return repo.PrintExplainIgnore(repo.CurrentWorktree, args...)
+		}
+
+		if auditExclude {
+			return repo.PrintExcludeAudit()
+		}
+
 		// No args: list excluded patterns
 		if len(args) == 0 {
 			return repo.PrintExcludedPatterns()
@@ -48,7 +73,12 @@ Use --rm flag to remove exclusions instead.`,
 		if removeExclude {
 			// Remove exclusions
 			for _, pattern := range args {
-				err := repo.UnexcludePattern(pattern)
+				var err error
+				if localExclude {
+					err = repo.LocalUnexcludePattern(pattern)
+				} else {
+					err = repo.UnexcludePattern(pattern)
+				}
 				if err != nil {
 					errors = append(errors, fmt.Sprintf("  %s: %v", pattern, err))
 				}
@@ -60,7 +90,12 @@ Use --rm flag to remove exclusions instead.`,
 		} else {
 			// Add exclusions
 			for _, pattern := range args {
-				err := repo.ExcludePattern(pattern)
+				var err error
+				if localExclude {
+					err = repo.LocalExcludePattern(pattern)
+				} else {
+					err = repo.ExcludePattern(pattern)
+				}
 				if err != nil {
 					errors = append(errors, fmt.Sprintf("  %s: %v", pattern, err))
 				}
@@ -78,5 +113,9 @@ Use --rm flag to remove exclusions instead.`,
 // NewExcludeCmd returns the exclude command
 func NewExcludeCmd() *cobra.Command {
 	excludeCmd.Flags().BoolVar(&removeExclude, "rm", false, "Remove patterns from exclude list")
+	excludeCmd.Flags().BoolVar(&localExclude, "local", false, "Only affect current worktree")
+	excludeCmd.Flags().BoolVar(&explainIgnore, "explain", false, "Show which ignore source matches each path")
+	excludeCmd.Flags().BoolVar(&auditExclude, "audit", false, "List what each exclude pattern matches across worktrees")
+	excludeCmd.MarkFlagsMutuallyExclusive("explain", "audit", "rm")
 	return excludeCmd
 }
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec <worktree> -- <command> [args...]",
+	Short: "Run a command in another worktree",
+	Long: `Run a command in another worktree without switching to it. The worktree can be given by name, branch or glob pattern, which must match exactly one worktree.
+
+The command runs from the worktree root with WRK_REPO, WRK_MAIN_PATH,
+WRK_WORKTREES_DIR, WRK_WORKTREE, WRK_WORKTREE_PATH and WRK_BRANCH set, and wrk
+exits with its exit code.
+
+Example:
+  wrk exec feature-x -- make test`,
+	Args: cobra.MinimumNArgs(2),
+	ValidArgsFunction: pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return pkg.GlobFilterComplete(args, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		wt, err := repo.FindWorktree(args[0])
+		if err != nil {
+			return err
+		}
+
+		command := args[1:]
+		if command[0] == "--" {
+			command = command[1:]
+		}
+		if len(command) == 0 {
+			return fmt.Errorf("command required after --")
+		}
+
+		err = repo.Exec(wt, command[0], command[1:]...)
+		var exitErr *pkg.ExitCodeError
+		if errors.As(err, &exitErr) {
+			// The command has already reported its own failure
+			cmd.SilenceErrors = true
+		}
+		return err
+	}),
+}
+
+// NewExecCmd returns the exec command
+func NewExecCmd() *cobra.Command {
+	// Flags after the worktree belong to the command being run
+	execCmd.Flags().SetInterspersed(false)
+	return execCmd
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export skip, exclude and always-copy settings",
+	Long:  `Export the skipped files and patterns, exclude patterns, always-copy paths and post-create commands into a YAML bundle that can be applied to another clone with 'import'. Writes to stdout if no file is given.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		bundle, err := repo.ExportBundle()
+		if err != nil {
+			return err
+		}
+
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		if err := pkg.WriteBundle(bundle, path); err != nil {
+			return err
+		}
+
+		if path != "" {
+			fmt.Printf("Exported settings to '%s'\n", path)
+		}
+		return nil
+	}),
+}
+
+// NewExportCmd returns the export command
+func NewExportCmd() *cobra.Command {
+	return exportCmd
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	foreachMatch    string
+	foreachParallel int
+)
+
+var foreachCmd = &cobra.Command{
+	Use:   "foreach [--match <glob>] [--parallel N] -- <command>",
+	Short: "Run a command in every worktree",
+	Long: `Run a shell command in every worktree, or in those whose name or branch matches --match. Output is streamed with each line prefixed by the worktree name.
+
+Use --parallel to run in several worktrees at once. Ends with a pass/fail
+summary and exits non-zero if the command failed anywhere.
+
+A single argument is run as a shell command line, so quote it to use pipes or
+&&. Several arguments are passed to the command as given.
+
+Examples:
+  wrk foreach -- git fetch
+  wrk foreach -- 'make build && make test'
+  wrk foreach --match 'feature/*' --parallel 4 -- go test ./...`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if foreachParallel < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+
+		worktrees, err := repo.SelectWorktrees(foreachMatch)
+		if err != nil {
+			return err
+		}
+
+		results := repo.Foreach(worktrees, pkg.ShellCommand(args), foreachParallel)
+		return pkg.PrintForeachSummary(results)
+	}),
+}
+
+// NewForeachCmd returns the foreach command
+func NewForeachCmd() *cobra.Command {
+	foreachCmd.Flags().StringVarP(&foreachMatch, "match", "m", "", "Only run in worktrees whose name or branch matches a glob")
+	foreachCmd.Flags().IntVarP(&foreachParallel, "parallel", "p", 1, "Number of worktrees to run in at once")
+	foreachCmd.RegisterFlagCompletionFunc("match", pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		return pkg.GlobFilterComplete(nil, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	return foreachCmd
+}
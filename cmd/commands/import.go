@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importYes    bool
+	importDryRun bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import skip, exclude and always-copy settings",
+	Long: `Apply a bundle created by 'export' to this repository. Settings that are already applied are left alone, so importing the same bundle twice is safe.
+
+Shows the changes that would be made and asks for confirmation before applying them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		bundle, err := pkg.ReadBundle(args[0])
+		if err != nil {
+			return err
+		}
+
+		changes, err := repo.PlanImport(bundle)
+		if err != nil {
+			return err
+		}
+
+		pkg.PrintImportPlan(changes)
+		if len(changes) == 0 || importDryRun {
+			return nil
+		}
+
+		if !importYes && !pkg.Confirm("Apply these changes?") {
+			fmt.Println("Import cancelled.")
+			return nil
+		}
+
+		if err := repo.ApplyImport(bundle, changes); err != nil {
+			return err
+		}
+
+		fmt.Printf("Imported %d setting(s)\n", len(changes))
+		return nil
+	}),
+}
+
+// NewImportCmd returns the import command
+func NewImportCmd() *cobra.Command {
+	importCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "Apply without asking for confirmation")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "Only show the changes that would be made")
+	return importCmd
+}
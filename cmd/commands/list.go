@@ -2,37 +2,122 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/bungogood/worktree/pkg"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listStatus    bool
+	listLong      bool
+	listUnmanaged bool
+	listAll       bool
+)
+
 var listCmd = &cobra.Command{
-	Use:               "list",
-	Aliases:           []string{"ls"},
-	Short:             "List all worktrees",
-	Long:              `Display all worktrees in the repository with their branches and paths.`,
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List all worktrees",
+	Long: `Display all worktrees in the repository with their branches and paths.
+
+Use --long to also show each worktree's base, how far it is ahead of and behind
+it, and when it was created. Use --unmanaged to find worktrees living outside
+.{repo}.worktrees that 'wrk adopt' can move in.
+
+Use --all to list the worktrees of every repository wrk has seen, which also
+works outside a repository.`,
 	Args:              cobra.NoArgs,
 	ValidArgsFunction: cobra.NoFileCompletions,
-	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+	RunE: listAllRepos(pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
 		if len(repo.Worktrees) == 0 {
 			fmt.Println("No worktrees found.")
 			return nil
 		}
 
+		if listUnmanaged {
+			unmanaged := repo.UnmanagedWorktrees()
+			if len(unmanaged) == 0 {
+				fmt.Println("No unmanaged worktrees.")
+			}
+			for _, wt := range unmanaged {
+				fmt.Println(repo.GetWorktreeDisplay(wt) + "  " + color.New(color.Faint).Sprint(wt.Path))
+			}
+			return nil
+		}
+
 		// Get sorted worktrees (main first, current second, then alphabetically)
 		worktrees := repo.SortedWorktrees()
 
 		// Display each worktree
 		for _, wt := range worktrees {
 			display := repo.GetWorktreeDisplay(&wt)
+			if listLong {
+				if details := repo.GetWorktreeDetails(&wt); details != "" {
+					display += "  " + color.New(color.Faint).Sprint(details)
+				}
+			}
 			fmt.Println(display)
 		}
+
+		if listStatus {
+			if _, err := repo.PrintSkipCheck(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})),
+}
+
+// listAllRepos lists every registered repository's worktrees with --all, and
+// runs listRepo otherwise
+func listAllRepos(listRepo func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !listAll {
+			return listRepo(cmd, args)
+		}
+
+		registry, err := pkg.LoadRegistry()
+		if err != nil {
+			return err
+		}
+		matches := registry.Matches()
+		if len(matches) == 0 {
+			fmt.Println("No worktrees found.")
+			return nil
+		}
+
+		cwd, _ := os.Getwd()
+		repo := ""
+		for _, match := range matches {
+			if match.Repo != repo {
+				repo = match.Repo
+				fmt.Println(color.New(color.Bold).Sprint(repo))
+			}
+
+			display := match.Worktree.Name
+			if match.Worktree.Branch != "" && match.Worktree.Branch != display {
+				display = fmt.Sprintf("%s [%s]", display, match.Worktree.Branch)
+			}
+			prefix := "  "
+			if cwd == match.Worktree.Path || strings.HasPrefix(cwd, match.Worktree.Path+string(filepath.Separator)) {
+				prefix = "* "
+				display = color.GreenString(display)
+			}
+			fmt.Println("  " + prefix + display + "  " + color.New(color.Faint).Sprint(match.Worktree.Path))
+		}
 		return nil
-	}),
+	}
 }
 
 // NewListCmd returns the list command
 func NewListCmd() *cobra.Command {
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "Show base, ahead/behind and creation time")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "List worktrees of every repository wrk has seen")
+	listCmd.Flags().BoolVar(&listUnmanaged, "unmanaged", false, "List worktrees outside .{repo}.worktrees")
+	listCmd.Flags().BoolVar(&listStatus, "status", false, "Also report skipped files whose upstream content has changed")
 	return listCmd
 }
@@ -7,11 +7,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var newSparse string
+
 var newCmd = &cobra.Command{
 	Use:   "new <branch> [name]",
 	Short: "Create a new branch as a worktree",
-	Long:  `Creates a new branch in a new worktree and navigates to it. Optionally specify a custom directory name.`,
-	Args:  cobra.RangeArgs(1, 2),
+	Long: `Creates a new branch in a new worktree and navigates to it. Optionally specify a custom directory name.
+
+Use --sparse to check out only the directories of a profile from sparseProfiles
+in the config.`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
 		branch := args[0]
 		name := branch
@@ -20,7 +25,13 @@ var newCmd = &cobra.Command{
 		}
 
 		// Create the new branch
-		worktree, err := repo.CreateNewBranch(branch, name)
+		var worktree *pkg.Worktree
+		var err error
+		if newSparse != "" {
+			worktree, err = repo.CreateSparseBranch(branch, name, newSparse)
+		} else {
+			worktree, err = repo.CreateNewBranch(branch, name)
+		}
 		if err != nil {
 			return err
 		}
@@ -33,5 +44,7 @@ var newCmd = &cobra.Command{
 
 // NewNewCmd returns the new command
 func NewNewCmd() *cobra.Command {
+	newCmd.Flags().StringVar(&newSparse, "sparse", "", "Only check out the directories of a sparse-checkout profile")
+	newCmd.RegisterFlagCompletionFunc("sparse", sparseProfileCompletion)
 	return newCmd
 }
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prRemote  string
+	prRefresh bool
+	prForce   bool
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr <number>",
+	Short: "Check out a pull request as a worktree",
+	Long: `Fetches a pull request head (refs/pull/<n>/head, or refs/merge-requests/<n>/head
+for GitLab) into a local pr/<n> branch, creates a worktree for it and navigates
+to it.
+
+Use --refresh to fetch new pushes into an existing pull request worktree, the
+current one if no number is given. A force-pushed pull request needs --force
+to reset the branch to it, as does an existing pr/<n> branch with commits that
+aren't in the pull request.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		number := 0
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid pull request number '%s'", args[0])
+			}
+			number = n
+		}
+
+		if prRefresh {
+			return refreshPull(repo, number)
+		}
+		if number == 0 {
+			return fmt.Errorf("pull request number required")
+		}
+
+		worktree, err := repo.CheckoutPull(number, prRemote, prForce)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Worktree created: '%s'\n", worktree.Branch)
+		pkg.ChangeDirectory(worktree.Path)
+		return nil
+	}),
+}
+
+// refreshPull updates the worktree for a pull request, or the current worktree
+func refreshPull(repo *pkg.Repo, number int) error {
+	worktree := repo.CurrentWorktree
+	if number != 0 {
+		worktree = repo.FindWorktreeByBranch(pkg.PullBranch(number))
+		if worktree == nil {
+			return fmt.Errorf("no worktree found for pull request %d", number)
+		}
+	}
+
+	result, err := repo.RefreshPull(worktree, prForce)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s\n", worktree.Branch, result)
+	return nil
+}
+
+// NewPRCmd returns the pr command
+func NewPRCmd() *cobra.Command {
+	prCmd.Flags().StringVar(&prRemote, "remote", "origin", "Remote to fetch the pull request from")
+	prCmd.Flags().BoolVar(&prRefresh, "refresh", false, "Fetch new pushes into an existing pull request worktree")
+	prCmd.Flags().BoolVarP(&prForce, "force", "f", false, "Reset pr/<n> to the pull request even if that drops commits")
+	return prCmd
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	removeStray bool
+)
+
+var repairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Fix problems found by doctor",
+	Long: `Fix the problems reported by 'doctor'. Prunes worktrees whose directories are gone, runs git worktree repair, re-links skipped files to main, drops always-copy paths whose source is missing, recreates .git/info/exclude and rolls back interrupted skips.
+
+Directories in the worktrees directory that git doesn't know about are only deleted with --remove-stray.`,
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		repaired, kept, err := repo.Repair(removeStray)
+
+		for _, issue := range repaired {
+			fmt.Printf("Fixed %s\n", issue)
+		}
+		for _, issue := range kept {
+			color.Yellow("Skipped %s: left in place (use --remove-stray to delete it)\n", issue)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(repaired) == 0 && len(kept) == 0 {
+			fmt.Println("Nothing to repair.")
+		}
+		return nil
+	}),
+}
+
+// NewRepairCmd returns the repair command
+func NewRepairCmd() *cobra.Command {
+	repairCmd.Flags().BoolVar(&removeStray, "remove-stray", false, "Delete directories in the worktrees directory that are not git worktrees")
+	return repairCmd
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var reviewTTL string
+
+var reviewCmd = &cobra.Command{
+	Use:   "review <ref>",
+	Short: "Check out a ref in a throwaway worktree",
+	Long: `Creates a detached-HEAD worktree for a branch, tag or commit under the
+review/ namespace and navigates to it. No local branch is created.
+
+The worktree expires after --ttl (90m, 12h, 3d). Expired worktrees are removed
+by 'wrk clean --expired', and the next wrk command offers to remove them.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		output, err := repo.RunGitCommand(nil, "for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/remotes", "refs/tags")
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return pkg.GlobFilterComplete(args, strings.Fields(string(output)), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		ttl, err := pkg.ParseTTL(reviewTTL)
+		if err != nil {
+			return err
+		}
+
+		worktree, err := repo.CreateReview(args[0], ttl)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Review worktree created: '%s' (expires %s)\n", worktree.Name, worktree.Meta.Expires.Format("2006-01-02 15:04"))
+		pkg.ChangeDirectory(worktree.Path)
+		return nil
+	}),
+}
+
+// NewReviewCmd returns the review command
+func NewReviewCmd() *cobra.Command {
+	reviewCmd.Flags().StringVar(&reviewTTL, "ttl", "3d", "How long to keep the worktree (e.g. 90m, 12h, 3d)")
+	return reviewCmd
+}
@@ -9,29 +9,52 @@ import (
 )
 
 var (
-	removeSkip bool
-	localSkip  bool
+	removeSkip  bool
+	localSkip   bool
+	checkSkip   bool
+	refreshSkip bool
+	skipMode    string
+	resumeSkip  bool
+	undoSkip    bool
 )
 
 var skipCmd = &cobra.Command{
-	Use:   "skip [file...]",
+	Use:   "skip [path...]",
 	Short: "Manage skipped file changes",
 	Long: `Manage files whose changes should be skipped without modifying .gitignore. Uses git update-index --skip-worktree.
 	
 With no arguments, lists all skipped files.
-With file arguments, marks files to have their changes skipped.
-Use --rm flag to unskip files instead.`,
+With path arguments, marks files to have their changes skipped. Paths may be
+tracked files, directories or glob patterns such as 'config/*.local.json'.
+Directory and glob patterns are remembered, so files added under them later
+are skipped in new worktrees too. A file under a remembered pattern can only
+be unskipped by unskipping the pattern.
+Use --rm flag to unskip files instead.
+
+Use --check to report skipped files whose upstream content has changed, and
+--refresh <file> to merge upstream changes into a skipped file's local copy
+and skip it again across all worktrees.
+
+Use --strategy to choose how skipped files in other worktrees follow the main
+worktree: symlink (default), copy (an independent copy) or hardlink. With no
+file arguments, --strategy sets the default for future skips.
+
+Skipping and unskipping across worktrees is all or nothing: if any worktree
+fails, every worktree is rolled back. Worktrees that don't track a file or
+don't have it are left out and reported. If a run is interrupted, use --resume to
+finish it or --undo to roll it back.`,
 	ValidArgsFunction: pkg.RepoCompletion(func(
 		repo *pkg.Repo,
 		cmd *cobra.Command,
 		args []string,
 		toComplete string) ([]string, cobra.ShellCompDirective) {
-		// If --rm flag is set, suggest skipped files
-		if removeSkip {
+		// If --rm or --refresh flag is set, suggest skipped files
+		if removeSkip || refreshSkip {
 			skipped, err := repo.ListSkippedFiles()
 			if err != nil {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
+			skipped = append(skipped, repo.ListSkipPatterns()...)
 
 			return pkg.GlobFilterComplete(args, skipped, toComplete), cobra.ShellCompDirectiveNoFileComp
 		}
@@ -39,6 +62,66 @@ Use --rm flag to unskip files instead.`,
 		return nil, cobra.ShellCompDirectiveDefault
 	}),
 	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if resumeSkip || undoSkip {
+			var operation string
+			var err error
+			if resumeSkip {
+				operation, err = repo.ResumeSkipJournal()
+			} else {
+				operation, err = repo.UndoSkipJournal()
+			}
+			if err != nil {
+				return err
+			}
+			if resumeSkip {
+				fmt.Printf("Resumed '%s'\n", operation)
+			} else {
+				fmt.Printf("Undid '%s'\n", operation)
+			}
+			return nil
+		}
+
+		if checkSkip {
+			count, err := repo.PrintSkipCheck()
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				fmt.Println("All skipped files are up to date.")
+			}
+			return nil
+		}
+
+		if refreshSkip {
+			if len(args) == 0 {
+				return fmt.Errorf("file required for --refresh")
+			}
+			for _, file := range args {
+				if err := repo.RefreshSkippedFile(file); err != nil {
+					return fmt.Errorf("%s: %w", file, err)
+				}
+			}
+			return nil
+		}
+
+		var strategy pkg.SkipStrategy
+		if skipMode != "" {
+			var err error
+			strategy, err = pkg.ParseSkipStrategy(skipMode)
+			if err != nil {
+				return err
+			}
+
+			// No args: set the default strategy
+			if len(args) == 0 {
+				if err := repo.SetDefaultSkipStrategy(strategy); err != nil {
+					return err
+				}
+				fmt.Printf("Default skip strategy set to '%s'\n", strategy)
+				return nil
+			}
+		}
+
 		// No args: list skipped files
 		if len(args) == 0 {
 			return repo.PrintSkippedFiles()
@@ -71,7 +154,7 @@ Use --rm flag to unskip files instead.`,
 				if localSkip {
 					err = repo.LocalSkipFile(file)
 				} else {
-					err = repo.SkipFile(file)
+					err = repo.SkipFile(file, strategy)
 				}
 				if err != nil {
 					errors = append(errors, fmt.Sprintf("  %s: %v", file, err))
@@ -91,5 +174,15 @@ Use --rm flag to unskip files instead.`,
 func NewSkipCmd() *cobra.Command {
 	skipCmd.Flags().BoolVar(&removeSkip, "rm", false, "Remove files from skip list")
 	skipCmd.Flags().BoolVar(&localSkip, "local", false, "Only affect current worktree (does not work in main worktree)")
+	skipCmd.Flags().BoolVar(&checkSkip, "check", false, "Report skipped files whose upstream content has changed")
+	skipCmd.Flags().BoolVar(&refreshSkip, "refresh", false, "Merge upstream changes into skipped files and skip them again")
+	skipCmd.Flags().BoolVar(&resumeSkip, "resume", false, "Finish an interrupted skip or unskip")
+	skipCmd.Flags().BoolVar(&undoSkip, "undo", false, "Roll back an interrupted skip or unskip")
+	skipCmd.MarkFlagsMutuallyExclusive("resume", "undo")
+	skipCmd.Flags().StringVar(&skipMode, "strategy", "", "How other worktrees follow main: symlink, copy or hardlink")
+	skipCmd.RegisterFlagCompletionFunc("strategy", cobra.FixedCompletions(
+		[]string{string(pkg.SkipSymlink), string(pkg.SkipCopy), string(pkg.SkipHardlink)},
+		cobra.ShellCompDirectiveNoFileComp,
+	))
 	return skipCmd
 }
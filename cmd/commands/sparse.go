@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var sparseFull bool
+
+var sparseCmd = &cobra.Command{
+	Use:   "sparse",
+	Short: "Change which directories the current worktree checks out",
+	Long: `Sparse-checkout profiles are named sets of directories in the config:
+
+  sparseProfiles:
+    api:
+      - services/api
+      - libs/common
+    web:
+      - services/web
+
+A worktree created with 'wrk new --sparse <profile>' checks out only files at
+the top level and in the profile's directories. Use these commands to change
+the current worktree's profile later.`,
+}
+
+// sparseProfileCompletion completes the profiles defined in the config
+var sparseProfileCompletion = pkg.RepoCompletion(func(repo *pkg.Repo, cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return pkg.GlobFilterComplete(args, repo.SparseProfileNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+})
+
+var sparseSetCmd = &cobra.Command{
+	Use:               "set <profile>... | --full",
+	Short:             "Check out only the directories of the given profiles",
+	ValidArgsFunction: sparseProfileCompletion,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if sparseFull == (len(args) > 0) {
+			return fmt.Errorf("give one or more profiles, or --full")
+		}
+
+		if repo.CurrentWorktree == nil {
+			return fmt.Errorf("not in a worktree")
+		}
+		wt := repo.CurrentWorktree
+		if err := repo.SetSparseProfile(wt, strings.Join(args, ",")); err != nil {
+			return err
+		}
+
+		if sparseFull {
+			fmt.Printf("Worktree '%s' now has a full checkout\n", wt.Name)
+		} else {
+			fmt.Printf("Worktree '%s' now uses profile '%s'\n", wt.Name, wt.Meta.Profile)
+		}
+		return nil
+	}),
+}
+
+var sparseAddCmd = &cobra.Command{
+	Use:               "add <profile>...",
+	Short:             "Also check out the directories of the given profiles",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: sparseProfileCompletion,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if repo.CurrentWorktree == nil {
+			return fmt.Errorf("not in a worktree")
+		}
+		wt := repo.CurrentWorktree
+
+		var errors []string
+		for _, profile := range args {
+			if err := repo.AddSparseProfile(wt, profile); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %v", profile, err))
+			}
+		}
+
+		fmt.Printf("Worktree '%s' uses profile '%s'\n", wt.Name, wt.Meta.Profile)
+		if len(errors) > 0 {
+			return fmt.Errorf("failed to add %d profile(s):\n%s", len(errors), strings.Join(errors, "\n"))
+		}
+		return nil
+	}),
+}
+
+var sparseListCmd = &cobra.Command{
+	Use:               "list",
+	Aliases:           []string{"ls"},
+	Short:             "List the profiles in the config, marking the current worktree's",
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		names := repo.SparseProfileNames()
+		if len(names) == 0 {
+			fmt.Printf("No sparse profiles defined in %s\n", repo.ConfigPath())
+			return nil
+		}
+
+		wt := repo.CurrentWorktree
+		for _, name := range names {
+			prefix := "  "
+			display := name
+			if wt != nil && repo.HasSparseProfile(wt, name) {
+				prefix = "* "
+				display = color.GreenString(display)
+			}
+			dirs := strings.Join(repo.Config.SparseProfiles[name], " ")
+			fmt.Println(prefix + display + "  " + color.New(color.Faint).Sprint(dirs))
+		}
+		return nil
+	}),
+}
+
+// NewSparseCmd returns the sparse command
+func NewSparseCmd() *cobra.Command {
+	sparseSetCmd.Flags().BoolVar(&sparseFull, "full", false, "Check out every file again")
+	sparseCmd.AddCommand(sparseSetCmd)
+	sparseCmd.AddCommand(sparseAddCmd)
+	sparseCmd.AddCommand(sparseListCmd)
+	return sparseCmd
+}
@@ -1,16 +1,23 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/bungogood/worktree/pkg"
 	"github.com/spf13/cobra"
 )
 
+var switchGlobal bool
+
 var switchCmd = &cobra.Command{
 	Use:   "switch [branch]",
 	Short: "Switch to a worktree",
-	Long:  `Switch to an existing worktree by branch name. If no branch is specified, switches to the main worktree.`,
-	Args:  cobra.MaximumNArgs(1),
-	ValidArgsFunction: pkg.RepoCompletion(func(
+	Long: `Switch to an existing worktree by branch name. If no branch is specified, switches to the main worktree.
+
+With --global, switch to a worktree of any repository wrk has seen, matching
+its name or branch, optionally prefixed with the repository name (repo/name).`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: globalSwitchCompletion(pkg.RepoCompletion(func(
 		repo *pkg.Repo,
 		cmd *cobra.Command,
 		args []string,
@@ -24,8 +31,8 @@ var switchCmd = &cobra.Command{
 		args = append(args, repo.CurrentWorktree.Branch)
 
 		return pkg.GlobFilterComplete(args, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
-	}),
-	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+	})),
+	RunE: globalSwitch(pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
 		var worktree *pkg.Worktree
 
 		// If no args, switch to main worktree
@@ -43,10 +50,59 @@ var switchCmd = &cobra.Command{
 		// Switch to the worktree
 		pkg.ChangeDirectory(worktree.Path)
 		return nil
-	}),
+	})),
+}
+
+// globalSwitchCompletion completes worktrees from the global registry with
+// --global, and from the current repository otherwise
+func globalSwitchCompletion(repoCompletion cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if !switchGlobal {
+			return repoCompletion(cmd, args, toComplete)
+		}
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		registry, err := pkg.LoadRegistry()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var aliases []string
+		for _, match := range registry.Matches() {
+			aliases = append(aliases, match.Aliases()...)
+		}
+		return pkg.GlobFilterComplete(args, aliases, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// globalSwitch switches to a worktree of any registered repository with
+// --global, which works outside a repository, and runs repoSwitch otherwise
+func globalSwitch(repoSwitch func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if !switchGlobal {
+			return repoSwitch(cmd, args)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("a worktree pattern is required with --global")
+		}
+
+		registry, err := pkg.LoadRegistry()
+		if err != nil {
+			return err
+		}
+		match, err := registry.Find(args[0])
+		if err != nil {
+			return err
+		}
+
+		pkg.ChangeDirectory(match.Worktree.Path)
+		return nil
+	}
 }
 
 // NewSwitchCmd returns the switch command
 func NewSwitchCmd() *cobra.Command {
+	switchCmd.Flags().BoolVarP(&switchGlobal, "global", "g", false, "Search worktrees of every repository wrk has seen")
 	return switchCmd
 }
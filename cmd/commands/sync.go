@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncTo     []string
+	syncAll    bool
+	syncForce  bool
+	syncYes    bool
+	syncDryRun bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <path> [--to <worktree>...|--all]",
+	Short: "Push files from the current worktree to others",
+	Long: `Push a file or directory from the current worktree to other worktrees. Paths are relative to the worktree root.
+
+Shows a diff for each target and asks for confirmation before copying. Files
+are only added or updated, never deleted.
+
+A target's copy counts as edited if it differs from what was last synced to it
+and from its committed version. If any target has edits, nothing is synced
+unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	}),
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		if !syncAll && len(syncTo) == 0 {
+			return fmt.Errorf("specify target worktrees with --to or use --all")
+		}
+
+		var targets []*pkg.Worktree
+		if syncAll {
+			for i := range repo.Worktrees {
+				targets = append(targets, &repo.Worktrees[i])
+			}
+		} else {
+			for _, pattern := range syncTo {
+				wt, err := repo.FindWorktree(pattern)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, wt)
+			}
+		}
+
+		plan, err := repo.PlanSync(args[0], targets)
+		if err != nil {
+			return err
+		}
+
+		total := pkg.PrintSyncPlan(plan)
+		if total == 0 || syncDryRun {
+			return nil
+		}
+
+		if !syncYes && !pkg.Confirm(fmt.Sprintf("Sync %d file(s)?", total)) {
+			fmt.Println("Sync cancelled.")
+			return nil
+		}
+
+		if err := repo.ApplySync(plan, syncForce); err != nil {
+			return err
+		}
+
+		fmt.Printf("Synced %d file(s)\n", total)
+		return nil
+	}),
+}
+
+// NewSyncCmd returns the sync command
+func NewSyncCmd() *cobra.Command {
+	syncCmd.Flags().StringArrayVarP(&syncTo, "to", "t", nil, "Target worktree (repeatable)")
+	syncCmd.Flags().BoolVarP(&syncAll, "all", "a", false, "Sync to all other worktrees")
+	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "Overwrite files edited in the target")
+	syncCmd.Flags().BoolVarP(&syncYes, "yes", "y", false, "Sync without asking for confirmation")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Only show what would be synced")
+	syncCmd.MarkFlagsMutuallyExclusive("to", "all")
+	syncCmd.RegisterFlagCompletionFunc("to", pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		return pkg.GlobFilterComplete(syncTo, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	return syncCmd
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateRebase    bool
+	updateMerge     bool
+	updateMatch     string
+	updateAutostash bool
+	updateNoFetch   bool
+)
+
+var updateCmd = &cobra.Command{
+	Use:   "update [--rebase|--merge] [--match <glob>]",
+	Short: "Bring every worktree up to date with its upstream",
+	Long: `Fetch once, then update each worktree's branch from the base it was created from with 'wrk new', or else from its upstream. Use --match to only update worktrees whose name or branch matches a glob.
+
+By default branches are only fast-forwarded, and branches with their own
+commits are reported as diverged. Use --rebase or --merge to update those too.
+
+Worktrees with uncommitted changes are skipped unless --autostash is given. If
+a rebase or merge hits conflicts it is aborted, leaving that worktree as it
+was, and the remaining worktrees are still updated.`,
+	Args: cobra.NoArgs,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		worktrees, err := repo.SelectWorktrees(updateMatch)
+		if err != nil {
+			return err
+		}
+
+		opts := pkg.UpdateOptions{
+			Method:    pkg.UpdateFastForward,
+			Autostash: updateAutostash,
+			NoFetch:   updateNoFetch,
+		}
+		if updateRebase {
+			opts.Method = pkg.UpdateRebase
+		} else if updateMerge {
+			opts.Method = pkg.UpdateMerge
+		}
+
+		results, err := repo.UpdateWorktrees(worktrees, opts)
+		if err != nil {
+			return err
+		}
+
+		return pkg.PrintUpdateResults(results)
+	}),
+}
+
+// NewUpdateCmd returns the update command
+func NewUpdateCmd() *cobra.Command {
+	updateCmd.Flags().BoolVar(&updateRebase, "rebase", false, "Rebase branches with their own commits")
+	updateCmd.Flags().BoolVar(&updateMerge, "merge", false, "Merge into branches with their own commits")
+	updateCmd.Flags().StringVarP(&updateMatch, "match", "m", "", "Only update worktrees whose name or branch matches a glob")
+	updateCmd.Flags().BoolVar(&updateAutostash, "autostash", false, "Stash uncommitted changes around the update")
+	updateCmd.Flags().BoolVar(&updateNoFetch, "no-fetch", false, "Don't fetch before updating")
+	updateCmd.MarkFlagsMutuallyExclusive("rebase", "merge")
+	updateCmd.RegisterFlagCompletionFunc("match", pkg.RepoCompletion(func(
+		repo *pkg.Repo,
+		cmd *cobra.Command,
+		args []string,
+		toComplete string) ([]string, cobra.ShellCompDirective) {
+		return pkg.GlobFilterComplete(nil, repo.WorktreeAliases(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+
+	return updateCmd
+}
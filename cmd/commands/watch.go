@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/spf13/cobra"
+)
+
+var watchDebounce time.Duration
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep always-copy paths in sync across worktrees",
+	Long: `Watch the main worktree's always-copy paths and mirror changes into every other worktree. Runs in the foreground until interrupted.
+
+Changes are synced once the files have been quiet for the --debounce period.
+Files edited in another worktree since they were last synced are left alone and
+reported as conflicts; use 'wrk sync --force' to overwrite them. Files deleted
+in the main worktree are not deleted elsewhere.
+
+Activity is printed and appended to .sync.log in the worktrees directory.`,
+	Args: cobra.NoArgs,
+	RunE: pkg.RepoCommand(func(repo *pkg.Repo, cmd *cobra.Command, args []string) error {
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			close(stop)
+		}()
+
+		return repo.WatchAlwaysCopy(stop, pkg.WatchOptions{Debounce: watchDebounce})
+	}),
+}
+
+// NewWatchCmd returns the watch command
+func NewWatchCmd() *cobra.Command {
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 300*time.Millisecond, "How long files must be quiet before syncing")
+	return watchCmd
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bungogood/worktree/pkg"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	workspaceFile  string
+	workspaceForce bool
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:     "workspace",
+	Aliases: []string{"ws"},
+	Short:   "Work on the same branch across several repositories",
+	Long: `A workspace is a set of repositories listed in a wrk-workspace.yml file:
+
+  name: shop
+  repos:
+    - backend
+    - frontend
+    - proto
+
+Each workspace directory in .{name}.workspaces links the worktrees of one
+branch side by side. The file is found with --file, $WRK_WORKSPACE, or by
+searching from the current directory upwards.`,
+}
+
+// workspaceCompletion completes workspace directory names
+func workspaceCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ws, err := pkg.FindWorkspace(workspaceFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	entries, err := ws.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return pkg.GlobFilterComplete(args, names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+var workspaceNewCmd = &cobra.Command{
+	Use:               "new <branch>",
+	Short:             "Create a branch worktree in every repository of the workspace",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := pkg.FindWorkspace(workspaceFile)
+		if err != nil {
+			return err
+		}
+
+		dir, err := ws.New(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Workspace created: '%s'\n", filepath.Base(dir))
+		pkg.ChangeDirectory(dir)
+		return nil
+	},
+}
+
+var workspaceSwitchCmd = &cobra.Command{
+	Use:               "switch <name>",
+	Short:             "Switch to a workspace directory",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: workspaceCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := pkg.FindWorkspace(workspaceFile)
+		if err != nil {
+			return err
+		}
+
+		entry, err := ws.Find(args[0])
+		if err != nil {
+			return err
+		}
+
+		pkg.ChangeDirectory(entry.Path)
+		return nil
+	},
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:               "list",
+	Aliases:           []string{"ls"},
+	Short:             "List workspaces and the repositories in each",
+	Args:              cobra.NoArgs,
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := pkg.FindWorkspace(workspaceFile)
+		if err != nil {
+			return err
+		}
+
+		entries, err := ws.List()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("No workspaces found.")
+			return nil
+		}
+
+		for _, entry := range entries {
+			line := fmt.Sprintf("  %s  %s", entry.Name, strings.Join(entry.Repos, " "))
+			if len(entry.Missing) > 0 {
+				line += "  " + color.YellowString("missing: %s", strings.Join(entry.Missing, " "))
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var workspaceRemoveCmd = &cobra.Command{
+	Use:               "remove <name>...",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a workspace's worktrees from every repository",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: workspaceCompletion,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := pkg.FindWorkspace(workspaceFile)
+		if err != nil {
+			return err
+		}
+
+		cwd, _ := os.Getwd()
+		var removed []string
+		var errors []string
+		removedCurrent := false
+
+		for _, pattern := range args {
+			entry, err := ws.Find(pattern)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("  %v", err))
+				continue
+			}
+			if err := ws.Remove(entry, workspaceForce); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %v", entry.Name, err))
+				continue
+			}
+			removed = append(removed, entry.Name)
+			if strings.HasPrefix(cwd, entry.Path) {
+				removedCurrent = true
+			}
+		}
+
+		if len(removed) > 0 {
+			fmt.Printf("Removed %d workspace(s): %s\n", len(removed), strings.Join(removed, ", "))
+		}
+
+		if len(errors) > 0 {
+			return fmt.Errorf("failed to remove %d workspace(s):\n%s", len(errors), strings.Join(errors, "\n"))
+		}
+
+		// If we removed the current workspace, cd to the directory holding the workspace file
+		if removedCurrent {
+			pkg.ChangeDirectory(ws.Home())
+		}
+		return nil
+	},
+}
+
+// NewWorkspaceCmd returns the workspace command
+func NewWorkspaceCmd() *cobra.Command {
+	workspaceCmd.PersistentFlags().StringVarP(&workspaceFile, "file", "f", "", "Workspace file (default: $WRK_WORKSPACE or wrk-workspace.yml in a parent directory)")
+	workspaceRemoveCmd.Flags().BoolVarP(&workspaceForce, "force", "D", false, "Force delete the branches (like git branch -D)")
+
+	workspaceCmd.AddCommand(workspaceNewCmd)
+	workspaceCmd.AddCommand(workspaceSwitchCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+	workspaceCmd.AddCommand(workspaceRemoveCmd)
+	return workspaceCmd
+}
@@ -35,8 +35,11 @@ wrk() {
 
     local dir_path=""
     local exit_code=0
+    local status_file
+    status_file=$(mktemp "${TMPDIR:-/tmp}/wrk.XXXXXX") || return 1
 
-    # Stream output line by line and check for delimiter
+    # Stream output line by line and check for delimiter. The exit code goes
+    # through a file, since 'wait' on a process substitution needs bash 4.4
     while IFS= read -r line; do
         if [[ "$line" == %s* ]]; then
             # Found delimiter, extract directory path
@@ -45,10 +48,11 @@ wrk() {
             # Regular output, print immediately
             echo "$line"
         fi
-    done < <(worktree "$@" 2>&1)
+    done < <(worktree "$@" 2>&1; echo $? > "$status_file")
 
-    # Capture the exit code from the worktree command
-    exit_code=${PIPESTATUS[0]}
+    # The substitution holds the pipe open until it exits, so the file is written
+    exit_code=$(cat "$status_file")
+    rm -f "$status_file"
 
     # If we found a directory path, change to it
     if [ -n "$dir_path" ] && [ -d "$dir_path" ]; then
@@ -35,4 +35,22 @@ func init() {
 	RootCmd.AddCommand(commands.NewSkipCmd())
 	RootCmd.AddCommand(commands.NewExcludeCmd())
 	RootCmd.AddCommand(commands.NewCopyCmd())
+	RootCmd.AddCommand(commands.NewDoctorCmd())
+	RootCmd.AddCommand(commands.NewRepairCmd())
+	RootCmd.AddCommand(commands.NewExportCmd())
+	RootCmd.AddCommand(commands.NewImportCmd())
+	RootCmd.AddCommand(commands.NewSyncCmd())
+	RootCmd.AddCommand(commands.NewWatchCmd())
+	RootCmd.AddCommand(commands.NewDiffCmd())
+	RootCmd.AddCommand(commands.NewForeachCmd())
+	RootCmd.AddCommand(commands.NewExecCmd())
+	RootCmd.AddCommand(commands.NewUpdateCmd())
+	RootCmd.AddCommand(commands.NewReviewCmd())
+	RootCmd.AddCommand(commands.NewCleanCmd())
+	RootCmd.AddCommand(commands.NewPRCmd())
+	RootCmd.AddCommand(commands.NewCloneCmd())
+	RootCmd.AddCommand(commands.NewInitCmd())
+	RootCmd.AddCommand(commands.NewAdoptCmd())
+	RootCmd.AddCommand(commands.NewWorkspaceCmd())
+	RootCmd.AddCommand(commands.NewSparseCmd())
 }
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/bungogood/worktree/cmd"
+	"github.com/bungogood/worktree/pkg"
 )
 
 func main() {
 	if err := cmd.RootCmd.Execute(); err != nil {
+		// Pass through the exit code of commands run by exec
+		var exitErr *pkg.ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
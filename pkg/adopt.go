@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// FindUnmanaged returns the unmanaged worktrees matching a path, directory
+// name or branch, any of which may be a glob
+func (r *Repo) FindUnmanaged(pattern string) ([]*Worktree, error) {
+	absPattern, err := filepath.Abs(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", pattern, err)
+	}
+
+	var matches []*Worktree
+	for _, wt := range r.UnmanagedWorktrees() {
+		for _, candidate := range [][2]string{{absPattern, wt.Path}, {pattern, wt.Name}, {pattern, wt.Branch}} {
+			if matched, _ := filepath.Match(candidate[0], candidate[1]); matched && candidate[1] != "" {
+				matches = append(matches, wt)
+				break
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no unmanaged worktree found matching '%s'", pattern)
+	}
+	return matches, nil
+}
+
+// AdoptWorktree moves a worktree created outside wrk into WorktreesDir and
+// sets it up the way a new worktree is: skip symlinks are pointed back at the
+// main worktree, then skip and always-copy settings are applied without
+// replacing local changes. Post-create commands are not run.
+func (r *Repo) AdoptWorktree(wt *Worktree) error {
+	if r.IsMainWorktree(wt) {
+		return fmt.Errorf("cannot adopt the main worktree")
+	}
+	if strings.HasPrefix(wt.Path, r.WorktreesDir+string(filepath.Separator)) {
+		return fmt.Errorf("already in %s", r.WorktreesDir)
+	}
+
+	if err := r.MoveIntoWorktreesDir(wt); err != nil {
+		return err
+	}
+
+	var errors []string
+
+	// Relative or stale links broke when the worktree moved
+	issues, err := r.skipLinkIssues(wt)
+	if err != nil {
+		errors = append(errors, err.Error())
+	}
+	for _, issue := range issues {
+		if err := r.relinkSkippedFile(wt, issue.Path); err != nil {
+			errors = append(errors, fmt.Sprintf("file %s: %v", issue.Path, err))
+		}
+	}
+	if len(errors) > 0 {
+		color.Yellow("Warning: failed to fix skip links:\n%s\n", strings.Join(errors, "\n"))
+	}
+
+	if err := r.applySkipSettingsToWorktree(wt); err != nil {
+		color.Yellow("Warning: failed to apply skip settings: %v\n", err)
+	}
+
+	if err := r.ApplyAlwaysCopy(wt, ConflictSkip); err != nil {
+		color.Yellow("Warning: failed to apply always-copy: %v\n", err)
+	}
+
+	if wt.Meta.Created.IsZero() && wt.Branch != "" {
+		r.recordCreation(wt, newCreationMeta(r.branchUpstream(wt.Branch)))
+	}
+
+	return nil
+}
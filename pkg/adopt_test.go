@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAdoptWorktree_KeepsLocalChanges(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, "local.json"), "main\n")
+	writeTestFile(t, filepath.Join(main, "notes.txt"), "notes\n")
+	runGit(t, main, "add", "local.json", "notes.txt")
+	runGit(t, main, "commit", "-m", "files")
+	if err := repo.SkipFile("local.json", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	if err := repo.SkipFile("notes.txt", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(main, ".env"), "main\n")
+	repo.Config = &Config{Copy: []string{".env"}}
+	if err := repo.SaveConfig(); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	// A worktree made by hand, with a skip link that breaks when it moves
+	external := filepath.Join(t.TempDir(), "ext")
+	runGit(t, main, "worktree", "add", "-b", "ext", external)
+	runGit(t, external, "update-index", "--skip-worktree", "local.json")
+	if err := os.Remove(filepath.Join(external, "local.json")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if err := os.Symlink("../missing/local.json", filepath.Join(external, "local.json")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	writeTestFile(t, filepath.Join(external, "notes.txt"), "my notes\n")
+	writeTestFile(t, filepath.Join(external, ".env"), "mine\n")
+
+	repo = reloadTestRepo(t)
+	matches, err := repo.FindUnmanaged(external)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected to find ext by path, got %v, %v", matches, err)
+	}
+	if _, err := repo.FindUnmanaged("nothing-*"); err == nil {
+		t.Fatalf("expected no match")
+	}
+
+	wt := matches[0]
+	if err := repo.AdoptWorktree(wt); err != nil {
+		t.Fatalf("AdoptWorktree failed: %v", err)
+	}
+	if wt.Path != filepath.Join(repo.WorktreesDir, "ext") {
+		t.Fatalf("expected ext to move into %s, got %s", repo.WorktreesDir, wt.Path)
+	}
+
+	if target, err := os.Readlink(filepath.Join(wt.Path, "local.json")); err != nil || target != filepath.Join(main, "local.json") {
+		t.Fatalf("expected local.json to link to main, got %q, %v", target, err)
+	}
+	for file, want := range map[string]string{"notes.txt": "my notes\n", ".env": "mine\n"} {
+		data, err := os.ReadFile(filepath.Join(wt.Path, file))
+		if err != nil || string(data) != want {
+			t.Fatalf("expected %s to keep %q, got %q, %v", file, want, data, err)
+		}
+	}
+
+	repo = reloadTestRepo(t)
+	if len(repo.UnmanagedWorktrees()) != 0 {
+		t.Fatalf("expected no unmanaged worktrees after adopting")
+	}
+}
@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// starterConfig is written to .config.yml by wrk clone and wrk init
+const starterConfig = `# wrk configuration
+
+# Automatically delete the branch when removing a worktree
+deleteBranchWithWorktree: false
+
+# Files to automatically copy to new worktrees
+copy: []
+
+# Commands to run after creating new worktrees
+commands: []
+`
+
+// CloneOptions controls Clone
+type CloneOptions struct {
+	NoCheckout bool     // Check out only top-level files in the main worktree, work happens in worktrees
+	Filter     string   // Partial clone filter, e.g. blob:none
+	Branches   []string // Branches to create worktrees for
+}
+
+// CloneDir returns the directory git clone would use for a URL
+func CloneDir(url string) string {
+	name := filepath.Base(strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git"))
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// Clone clones a repository into the worktree layout: the main worktree in
+// dir, the .{repo}.worktrees directory beside it with a starter config, and
+// a worktree for each requested branch. The process moves into dir so the
+// repository can be loaded.
+func Clone(url, dir string, opts CloneOptions) (*Repo, []*Worktree, error) {
+	if dir == "" {
+		dir = CloneDir(url)
+	}
+
+	args := []string{"clone"}
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if opts.NoCheckout {
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, url, dir)
+	if output, err := RunCommand("git", args...); err != nil {
+		return nil, nil, fmt.Errorf("failed to clone %s: %w\n%s", url, err, strings.TrimSpace(string(output)))
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		return nil, nil, fmt.Errorf("failed to enter %s: %w", dir, err)
+	}
+
+	// A cone-mode sparse checkout with no directories keeps only top-level
+	// files, and git status stays clean since the rest are marked as outside
+	// the checkout rather than deleted
+	if opts.NoCheckout {
+		if output, err := RunCommand("git", "sparse-checkout", "set", "--cone"); err != nil {
+			return nil, nil, fmt.Errorf("failed to set sparse-checkout: %w\n%s", err, strings.TrimSpace(string(output)))
+		}
+		if output, err := RunCommand("git", "read-tree", "-mu", "HEAD"); err != nil {
+			return nil, nil, fmt.Errorf("failed to check out top-level files: %w\n%s", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	repo, err := LoadRepo()
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.NoCheckout {
+		if err := repo.allowSkippedFiles(repo.MainWorktree); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := repo.WriteStarterConfig(); err != nil {
+		return repo, nil, err
+	}
+
+	var worktrees []*Worktree
+	var errors []string
+	for _, branch := range opts.Branches {
+		if branch == repo.MainWorktree.Branch {
+			continue
+		}
+		wt, err := repo.AddExistingBranch(branch, branch, "origin")
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("  %s: %v", branch, err))
+			continue
+		}
+		worktrees = append(worktrees, wt)
+	}
+	if len(errors) > 0 {
+		return repo, worktrees, fmt.Errorf("failed to create %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+	return repo, worktrees, nil
+}
+
+// WriteStarterConfig creates the worktrees directory and a commented
+// .config.yml, leaving an existing config alone
+func (r *Repo) WriteStarterConfig() error {
+	if err := r.EnsureWorktreesDir(); err != nil {
+		return fmt.Errorf("failed to create worktrees directory: %w", err)
+	}
+	if _, err := os.Stat(r.ConfigPath()); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(r.ConfigPath(), []byte(starterConfig), 0644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return r.reloadConfig()
+}
+
+// reloadConfig re-reads the config file
+func (r *Repo) reloadConfig() error {
+	config, err := r.LoadConfig()
+	if err != nil {
+		return err
+	}
+	r.Config = config
+	return nil
+}
+
+// UnmanagedWorktrees returns worktrees other than the main one that live
+// outside WorktreesDir, in list order
+func (r *Repo) UnmanagedWorktrees() []*Worktree {
+	var unmanaged []*Worktree
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		if !r.IsMainWorktree(wt) && !strings.HasPrefix(wt.Path, r.WorktreesDir+string(filepath.Separator)) {
+			unmanaged = append(unmanaged, wt)
+		}
+	}
+	return unmanaged
+}
+
+// MoveIntoWorktreesDir moves a worktree into WorktreesDir under its directory
+// name with git worktree move, updating wt.Path
+func (r *Repo) MoveIntoWorktreesDir(wt *Worktree) error {
+	if err := r.EnsureWorktreesDir(); err != nil {
+		return fmt.Errorf("failed to create worktrees directory: %w", err)
+	}
+
+	dest := r.GetWorktreePath(filepath.Base(wt.Path))
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+
+	if _, err := r.RunGitCommand(nil, "worktree", "move", wt.Path, dest); err != nil {
+		return fmt.Errorf("failed to move worktree: %w", err)
+	}
+	r.unregisterWorktree(wt)
+	wt.Path = dest
+	r.registerWorktree(wt)
+
+	// Metadata is keyed by path, so it moves too
+	if wt.Meta != (WorktreeMeta{}) {
+		if err := r.SetWorktreeMeta(wt, wt.Meta); err != nil {
+			color.Yellow("Warning: failed to move worktree metadata: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// Init adopts an existing clone: it creates the worktrees directory with a
+// starter config and adopts worktrees living elsewhere. It returns the
+// worktrees that were moved.
+func (r *Repo) Init() ([]*Worktree, error) {
+	if err := r.WriteStarterConfig(); err != nil {
+		return nil, err
+	}
+
+	var moved []*Worktree
+	var errors []string
+	for _, wt := range r.UnmanagedWorktrees() {
+		from := wt.Path
+		if err := r.AdoptWorktree(wt); err != nil {
+			errors = append(errors, fmt.Sprintf("  %s: %v", from, err))
+			continue
+		}
+		moved = append(moved, wt)
+	}
+
+	if len(errors) > 0 {
+		return moved, fmt.Errorf("failed to move %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+	return moved, nil
+}
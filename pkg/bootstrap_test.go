@@ -0,0 +1,132 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupTestRemote creates a bare repository with main and feature branches
+func setupTestRemote(t *testing.T) string {
+	t.Helper()
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	remoteDir := filepath.Join(t.TempDir(), "project.git")
+	if err := os.MkdirAll(filepath.Join(main, "src"), 0755); err != nil {
+		t.Fatalf("failed to create src: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "src", "app.go"), "package app\n")
+	runGit(t, main, "add", "src")
+	runGit(t, main, "commit", "-m", "src")
+
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+	runGit(t, main, "push", "origin", "main", "main:feature")
+	runGit(t, remoteDir, "symbolic-ref", "HEAD", "refs/heads/main")
+	return remoteDir
+}
+
+func TestClone_CreatesLayoutAndWorktrees(t *testing.T) {
+	remoteDir := setupTestRemote(t)
+	parent := t.TempDir()
+	if err := os.Chdir(parent); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, worktrees, err := Clone(remoteDir, "", CloneOptions{Branches: []string{"main", "feature"}})
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	if repo.Name != "project" || repo.WorktreesDir != filepath.Join(parent, ".project.worktrees") {
+		t.Fatalf("unexpected repo %s at %s", repo.Name, repo.WorktreesDir)
+	}
+	if _, err := os.Stat(repo.ConfigPath()); err != nil {
+		t.Fatalf("expected a starter config: %v", err)
+	}
+	if repo.Config == nil {
+		t.Fatalf("expected the starter config to be loaded")
+	}
+	if len(worktrees) != 1 || worktrees[0].Branch != "feature" {
+		t.Fatalf("expected a worktree for feature only, got %v", worktrees)
+	}
+	if _, err := os.Stat(filepath.Join(worktrees[0].Path, "README.md")); err != nil {
+		t.Fatalf("expected feature to be checked out: %v", err)
+	}
+}
+
+func TestClone_NoCheckoutKeepsMainEmpty(t *testing.T) {
+	remoteDir := setupTestRemote(t)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	repo, _, err := Clone(remoteDir, "sparse", CloneOptions{NoCheckout: true})
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	main := repo.MainWorktree.Path
+	assertExists(t, filepath.Join(main, "src", "app.go"), false)
+	assertExists(t, filepath.Join(main, "README.md"), true)
+	if status := gitOutput(t, main, "status", "--porcelain"); status != "" {
+		t.Fatalf("expected a clean main worktree, got %q", status)
+	}
+
+	// Files outside the sparse checkout aren't taken for skipped files
+	skipped, err := repo.ListSkippedFiles()
+	if err != nil {
+		t.Fatalf("ListSkippedFiles failed: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("expected no skipped files, got %v", skipped)
+	}
+
+	// Top-level files can be skipped, and git doesn't unskip them again
+	if err := repo.SkipFile("README.md", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	gitOutput(t, main, "status", "--porcelain")
+	skipped, err = repo.ListSkippedFiles()
+	if err != nil {
+		t.Fatalf("ListSkippedFiles failed: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != "README.md" {
+		t.Fatalf("expected README.md to stay skipped, got %v", skipped)
+	}
+
+	wt, err := repo.CreateNewBranch("work", "work")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(wt.Path, "src", "app.go"))
+	if err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("expected new worktrees to be fully checked out, got %v %v", info, err)
+	}
+}
+
+func TestInit_MovesExternalWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	external := filepath.Join(t.TempDir(), "hotfix")
+	runGit(t, repo.MainWorktree.Path, "worktree", "add", "-b", "hotfix", external)
+
+	repo = reloadTestRepo(t)
+	if got := repo.UnmanagedWorktrees(); len(got) != 1 || got[0].Path != external {
+		t.Fatalf("expected hotfix to be unmanaged, got %v", got)
+	}
+
+	moved, err := repo.Init()
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if len(moved) != 1 || moved[0].Path != filepath.Join(repo.WorktreesDir, "hotfix") {
+		t.Fatalf("unexpected moved worktrees %v", moved)
+	}
+
+	repo = reloadTestRepo(t)
+	if len(repo.UnmanagedWorktrees()) != 0 {
+		t.Fatalf("expected no unmanaged worktrees after init")
+	}
+	if _, err := os.Stat(repo.ConfigPath()); err != nil {
+		t.Fatalf("expected a starter config: %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle is a portable set of skip, exclude and always-copy settings
+type Bundle struct {
+	Skip           []string          `yaml:"skip,omitempty"`
+	SkipPatterns   []string          `yaml:"skipPatterns,omitempty"`
+	SkipStrategy   string            `yaml:"skipStrategy,omitempty"`
+	SkipStrategies map[string]string `yaml:"skipStrategies,omitempty"`
+	Exclude        []string          `yaml:"exclude,omitempty"`
+	Copy           []string          `yaml:"copy,omitempty"`
+	CopyModes      map[string]string `yaml:"copyModes,omitempty"`
+	Commands       []string          `yaml:"commands,omitempty"`
+}
+
+// BundleChange is a single setting an import would add
+type BundleChange struct {
+	Kind  string // skip strategy, skip, skip pattern, exclude, copy or command
+	Value string
+}
+
+func (c BundleChange) String() string {
+	return fmt.Sprintf("%s %s", c.Kind, c.Value)
+}
+
+// ExportBundle collects the current skip list, exclude patterns, always-copy
+// paths and commands into a bundle
+func (r *Repo) ExportBundle() (*Bundle, error) {
+	skipped, err := r.ListSkippedFiles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(skipped)
+
+	excluded, err := r.ListExcludedPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{
+		Skip:         skipped,
+		SkipPatterns: r.ListSkipPatterns(),
+		Exclude:      excluded,
+	}
+
+	if r.Config != nil {
+		bundle.Copy = r.Config.Copy
+		bundle.CopyModes = r.Config.CopyModes
+		bundle.Commands = r.Config.Commands
+		bundle.SkipStrategy = r.Config.SkipStrategy
+		bundle.SkipStrategies = r.Config.SkipStrategies
+	}
+
+	return bundle, nil
+}
+
+// WriteBundle writes a bundle as YAML to a file, or stdout if path is empty
+func WriteBundle(bundle *Bundle, path string) error {
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return nil
+}
+
+// ReadBundle reads a bundle from a YAML file
+func ReadBundle(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var bundle Bundle
+	if err := yaml.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+
+	return &bundle, nil
+}
+
+// PlanImport returns the settings in a bundle that aren't already applied
+func (r *Repo) PlanImport(bundle *Bundle) ([]BundleChange, error) {
+	skipped, err := r.ListSkippedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := r.ListExcludedPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	var copyPaths, commands []string
+	if r.Config != nil {
+		copyPaths = r.Config.Copy
+		commands = r.Config.Commands
+	}
+
+	// The default strategy comes first so the skips below use it
+	var changes []BundleChange
+	if bundle.SkipStrategy != "" && SkipStrategy(bundle.SkipStrategy) != r.DefaultSkipStrategy() {
+		changes = append(changes, BundleChange{Kind: "skip strategy", Value: bundle.SkipStrategy})
+	}
+	for _, pattern := range bundle.SkipPatterns {
+		if !slices.Contains(r.ListSkipPatterns(), pattern) {
+			changes = append(changes, BundleChange{Kind: "skip pattern", Value: pattern})
+		}
+	}
+	for _, file := range bundle.Skip {
+		if !slices.Contains(skipped, file) {
+			changes = append(changes, BundleChange{Kind: "skip", Value: file})
+		}
+	}
+	for _, pattern := range bundle.Exclude {
+		if !slices.Contains(excluded, pattern) {
+			changes = append(changes, BundleChange{Kind: "exclude", Value: pattern})
+		}
+	}
+	for _, path := range bundle.Copy {
+		if !slices.Contains(copyPaths, path) {
+			changes = append(changes, BundleChange{Kind: "copy", Value: path})
+		}
+	}
+	for _, command := range bundle.Commands {
+		if !slices.Contains(commands, command) {
+			changes = append(changes, BundleChange{Kind: "command", Value: command})
+		}
+	}
+
+	return changes, nil
+}
+
+// ApplyImport applies planned bundle changes. Files already skipped by an
+// earlier pattern in the same import are left alone.
+func (r *Repo) ApplyImport(bundle *Bundle, changes []BundleChange) error {
+	var errors []string
+	configChanged := false
+
+	if r.Config == nil {
+		r.Config = &Config{}
+	}
+
+	for _, change := range changes {
+		var err error
+		switch change.Kind {
+		case "skip strategy":
+			var strategy SkipStrategy
+			if strategy, err = ParseSkipStrategy(change.Value); err == nil {
+				err = r.SetDefaultSkipStrategy(strategy)
+			}
+		case "skip pattern", "skip":
+			err = r.importSkip(bundle, change.Value)
+		case "exclude":
+			err = r.ExcludePattern(change.Value)
+		case "copy":
+			var mode CopyMode
+			if name, ok := bundle.CopyModes[change.Value]; ok {
+				mode, err = ParseCopyMode(name)
+			}
+			if err == nil {
+				err = r.AddAlwaysCopy(change.Value, mode)
+			}
+		case "command":
+			r.Config.Commands = append(r.Config.Commands, change.Value)
+			configChanged = true
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("  %s: %v", change, err))
+		}
+	}
+
+	if configChanged {
+		if err := r.SaveConfig(); err != nil {
+			errors = append(errors, fmt.Sprintf("  %v", err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to import %d setting(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+
+	return nil
+}
+
+// importSkip skips a file or pattern from a bundle with its recorded strategy
+func (r *Repo) importSkip(bundle *Bundle, pattern string) error {
+	skipped, err := r.getSkippedFilesInWorktree(r.MainWorktree, pattern)
+	if err != nil {
+		return err
+	}
+	if skipped[pattern] {
+		return nil
+	}
+
+	var strategy SkipStrategy
+	if name, ok := bundle.SkipStrategies[pattern]; ok {
+		strategy, err = ParseSkipStrategy(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.SkipFile(pattern, strategy)
+}
+
+// PrintImportPlan displays the changes an import would make
+func PrintImportPlan(changes []BundleChange) {
+	if len(changes) == 0 {
+		fmt.Println("Nothing to import, all settings are already applied.")
+		return
+	}
+
+	for _, change := range changes {
+		fmt.Printf("+ %s\n", change)
+	}
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExportImportBundle(t *testing.T) {
+	source := setupTestRepo(t)
+	if err := source.SetDefaultSkipStrategy(SkipHardlink); err != nil {
+		t.Fatalf("SetDefaultSkipStrategy failed: %v", err)
+	}
+	if err := source.SkipFile("README.md", SkipCopy); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	if err := source.ExcludePattern("*.log"); err != nil {
+		t.Fatalf("ExcludePattern failed: %v", err)
+	}
+	if err := source.AddAlwaysCopy(".env", ""); err != nil {
+		t.Fatalf("AddAlwaysCopy failed: %v", err)
+	}
+
+	bundle, err := source.ExportBundle()
+	if err != nil {
+		t.Fatalf("ExportBundle failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.yml")
+	if err := WriteBundle(bundle, path); err != nil {
+		t.Fatalf("WriteBundle failed: %v", err)
+	}
+
+	target := setupTestRepo(t)
+	bundle, err = ReadBundle(path)
+	if err != nil {
+		t.Fatalf("ReadBundle failed: %v", err)
+	}
+
+	changes, err := target.PlanImport(bundle)
+	if err != nil {
+		t.Fatalf("PlanImport failed: %v", err)
+	}
+	if len(changes) != 4 || changes[0].Kind != "skip strategy" {
+		t.Fatalf("expected the skip strategy and 3 more changes, got %v", changes)
+	}
+	if err := target.ApplyImport(bundle, changes); err != nil {
+		t.Fatalf("ApplyImport failed: %v", err)
+	}
+
+	if got := target.SkipStrategyFor("README.md"); got != SkipCopy {
+		t.Fatalf("expected imported strategy, got %q", got)
+	}
+	if got := target.DefaultSkipStrategy(); got != SkipHardlink {
+		t.Fatalf("expected imported default strategy, got %q", got)
+	}
+
+	// Importing again changes nothing
+	target = reloadTestRepo(t)
+	changes, err = target.PlanImport(bundle)
+	if err != nil {
+		t.Fatalf("PlanImport failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected import to be idempotent, got %v", changes)
+	}
+}
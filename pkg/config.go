@@ -1,22 +1,24 @@
 package pkg
 
 import (
-	"bufio"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
-	"github.com/fatih/color"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Copy                     []string `yaml:"copy"`
-	Commands                 []string `yaml:"commands"`
-	DeleteBranchWithWorktree bool     `yaml:"deleteBranchWithWorktree"`
+	Copy                     []string            `yaml:"copy"`
+	CopyModes                map[string]string   `yaml:"copyModes,omitempty"`
+	Skip                     []string            `yaml:"skip,omitempty"`
+	SkipStrategy             string              `yaml:"skipStrategy,omitempty"`
+	SkipStrategies           map[string]string   `yaml:"skipStrategies,omitempty"`
+	Commands                 []string            `yaml:"commands"`
+	DeleteBranchWithWorktree bool                `yaml:"deleteBranchWithWorktree"`
+	SparseProfiles           map[string][]string `yaml:"sparseProfiles,omitempty"`
 }
 
 // ConfigPath returns the path to the config file
@@ -68,8 +70,8 @@ func (r *Repo) SaveConfig() error {
 	return nil
 }
 
-// AddAlwaysCopy adds a path to the copy list
-func (r *Repo) AddAlwaysCopy(path string) error {
+// AddAlwaysCopy adds a path to the copy list, optionally with its own copy mode
+func (r *Repo) AddAlwaysCopy(path string, mode CopyMode) error {
 	if r.Config == nil {
 		r.Config = &Config{}
 	}
@@ -82,6 +84,12 @@ func (r *Repo) AddAlwaysCopy(path string) error {
 	}
 
 	r.Config.Copy = append(r.Config.Copy, path)
+	if mode != "" {
+		if r.Config.CopyModes == nil {
+			r.Config.CopyModes = make(map[string]string)
+		}
+		r.Config.CopyModes[path] = string(mode)
+	}
 	return r.SaveConfig()
 }
 
@@ -107,18 +115,21 @@ func (r *Repo) RemoveAlwaysCopy(path string) error {
 	}
 
 	r.Config.Copy = newCopy
+	delete(r.Config.CopyModes, path)
 	return r.SaveConfig()
 }
 
-// ApplyAlwaysCopy applies all always-copy paths to a worktree
-func (r *Repo) ApplyAlwaysCopy(destWt *Worktree) error {
+// ApplyAlwaysCopy applies all always-copy paths to a worktree, resolving
+// existing files with the given policy (empty to overwrite)
+func (r *Repo) ApplyAlwaysCopy(destWt *Worktree, conflict ConflictPolicy) error {
 	if r.Config == nil || len(r.Config.Copy) == 0 {
 		return nil
 	}
 
 	var errors []string
 	for _, path := range r.Config.Copy {
-		if err := r.CopyFromWorktree(r.MainWorktree, destWt, path, path); err != nil {
+		opts := CopyOptions{Mode: r.AlwaysCopyMode(path), Progress: IsTerminal(), Conflict: conflict}
+		if _, err := r.CopyFromWorktree(r.MainWorktree, destWt, path, path, opts); err != nil {
 			errors = append(errors, fmt.Sprintf("  %s: %v", path, err))
 		}
 	}
@@ -130,6 +141,29 @@ func (r *Repo) ApplyAlwaysCopy(destWt *Worktree) error {
 	return nil
 }
 
+// AlwaysCopyMode returns the copy mode for an always-copy path, empty for the default
+func (r *Repo) AlwaysCopyMode(path string) CopyMode {
+	if r.Config == nil {
+		return ""
+	}
+	return CopyMode(r.Config.CopyModes[path])
+}
+
+// alwaysCopyModeFor returns the copy mode of the always-copy path a file falls
+// under, empty for the default or if it isn't under one
+func (r *Repo) alwaysCopyModeFor(file string) CopyMode {
+	if r.Config == nil {
+		return ""
+	}
+	for _, path := range r.Config.Copy {
+		clean := filepath.Clean(path)
+		if file == clean || strings.HasPrefix(file, clean+string(filepath.Separator)) {
+			return r.AlwaysCopyMode(path)
+		}
+	}
+	return ""
+}
+
 // PrintAlwaysCopy displays all always-copy paths
 func (r *Repo) PrintAlwaysCopy() error {
 	if r.Config == nil || len(r.Config.Copy) == 0 {
@@ -138,7 +172,11 @@ func (r *Repo) PrintAlwaysCopy() error {
 	}
 
 	for _, path := range r.Config.Copy {
-		fmt.Printf("%s\n", path)
+		if mode := r.AlwaysCopyMode(path); mode != "" {
+			fmt.Printf("%s (%s)\n", path, mode)
+		} else {
+			fmt.Printf("%s\n", path)
+		}
 	}
 
 	return nil
@@ -156,60 +194,10 @@ func (r *Repo) RunPostCreateCommands(wt *Worktree) error {
 
 		cmd := exec.Command("bash", "-c", cmdStr)
 		cmd.Dir = wt.Path
+		cmd.Env = r.WorktreeEnv(wt)
 		cmd.Stdin = os.Stdin
 
-		// Create pipes for stdout and stderr
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stdout pipe: %w", err)
-		}
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return fmt.Errorf("failed to create stderr pipe: %w", err)
-		}
-
-		// Start the command
-		if err := cmd.Start(); err != nil {
-			return fmt.Errorf("command %d failed to start: %w", i+1, err)
-		}
-
-		// Function to prefix and print lines
-		printPrefixed := func(reader io.Reader, output *os.File) {
-			scanner := bufio.NewScanner(reader)
-			for scanner.Scan() {
-				text := scanner.Text()
-				if output == os.Stderr {
-					lowerText := strings.ToLower(text)
-					if strings.Contains(lowerText, "error") {
-						fmt.Fprintf(output, "! %s\n", color.RedString(text))
-					} else if strings.Contains(lowerText, "warn") {
-						fmt.Fprintf(output, "! %s\n", color.YellowString(text))
-					} else {
-						fmt.Fprintf(output, "! %s\n", color.RedString(text))
-					}
-				} else {
-					fmt.Fprintf(output, "> %s\n", color.BlueString(scanner.Text()))
-				}
-			}
-		}
-
-		// Process stdout and stderr concurrently
-		done := make(chan bool, 2)
-		go func() {
-			printPrefixed(stdout, os.Stdout)
-			done <- true
-		}()
-		go func() {
-			printPrefixed(stderr, os.Stderr)
-			done <- true
-		}()
-
-		// Wait for both goroutines to finish
-		<-done
-		<-done
-
-		// Wait for the command to complete
-		if err := cmd.Wait(); err != nil {
+		if err := runPrefixed(cmd, ""); err != nil {
 			return fmt.Errorf("command %d failed: %w", i+1, err)
 		}
 	}
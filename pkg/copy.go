@@ -1,15 +1,76 @@
 package pkg
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// CopyMode determines how file contents are copied
+type CopyMode string
+
+const (
+	CopyModeReflink  CopyMode = "reflink"  // Copy-on-write clone (btrfs, xfs), falling back to copy
+	CopyModeHardlink CopyMode = "hardlink" // Hard link sharing the source's data, falling back to copy
+	CopyModeCopy     CopyMode = "copy"     // Plain byte-for-byte copy
+)
+
+// errReflinkUnsupported is returned when the platform can't clone files
+var errReflinkUnsupported = errors.New("reflink not supported on this platform")
+
+// ParseCopyMode validates a copy mode name
+func ParseCopyMode(name string) (CopyMode, error) {
+	switch mode := CopyMode(name); mode {
+	case CopyModeReflink, CopyModeHardlink, CopyModeCopy:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown copy mode '%s' (expected reflink, hardlink or copy)", name)
+}
+
+// ConflictPolicy determines what happens when a destination file already
+// exists with different content
+type ConflictPolicy string
+
+const (
+	ConflictFail      ConflictPolicy = "fail"          // Report conflicts and copy nothing
+	ConflictOverwrite ConflictPolicy = "overwrite"     // Replace existing files
+	ConflictSkip      ConflictPolicy = "skip-existing" // Keep existing files
+	ConflictBackup    ConflictPolicy = "backup"        // Rename existing files with a .bak suffix, then copy
+)
+
+// backupSuffix is appended to files moved aside by ConflictBackup
+const backupSuffix = ".bak"
+
+// CopyOptions controls how CopyPathWithOptions copies a tree
+type CopyOptions struct {
+	Mode      CopyMode       // Defaults to reflink
+	Workers   int            // Number of files copied in parallel, defaults to the CPU count
+	Progress  bool           // Show a progress indicator on stderr
+	Exclude   []string       // Glob patterns matched against relative paths and base names
+	Gitignore bool           // Skip files ignored by git in the source
+	Conflict  ConflictPolicy // Defaults to overwrite
+}
+
+// CopyResult summarises a copy
+type CopyResult struct {
+	Files     int      // Files, including symlinks, that were written
+	Conflicts []string // Destination files that existed with different content
+}
+
 // CopyFromWorktree copies a file or directory from one worktree to another
-func (r *Repo) CopyFromWorktree(sourceWt *Worktree, destWt *Worktree, srcPath string, dstPath string) error {
+func (r *Repo) CopyFromWorktree(sourceWt *Worktree, destWt *Worktree, srcPath string, dstPath string, opts CopyOptions) (CopyResult, error) {
 	// Check if trying to copy from self to self
 	if sourceWt == destWt {
-		return fmt.Errorf("cannot copy from worktree to itself")
+		return CopyResult{}, fmt.Errorf("cannot copy from worktree to itself")
 	}
 
 	// Build full paths
@@ -17,11 +78,12 @@ func (r *Repo) CopyFromWorktree(sourceWt *Worktree, destWt *Worktree, srcPath st
 	fullDstPath := filepath.Join(destWt.Path, dstPath)
 
 	// Perform the copy
-	if err := CopyPath(fullSrcPath, fullDstPath); err != nil {
-		return fmt.Errorf("failed to copy: %w", err)
+	result, err := CopyPathWithOptions(fullSrcPath, fullDstPath, opts)
+	if err != nil {
+		return result, fmt.Errorf("failed to copy: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // GetSourceWorktree determines the source worktree based on a name/branch or defaults to main
@@ -32,3 +94,540 @@ func (r *Repo) GetSourceWorktree(name string) (*Worktree, error) {
 	// Default to main worktree
 	return r.MainWorktree, nil
 }
+
+// CopyPath copies a file or directory using the default options
+func CopyPath(src string, dst string) error {
+	_, err := CopyPathWithOptions(src, dst, CopyOptions{})
+	return err
+}
+
+// copyJob is a single file, symlink or directory to copy
+type copyJob struct {
+	src     string
+	dst     string
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+	link    string // Symlink target, empty for regular files and directories
+	backup  string // Where to move the existing destination before copying, if anywhere
+}
+
+// CopyPathWithOptions copies a file or directory, preserving symlinks,
+// permissions and modification times. The whole tree is planned and checked
+// for conflicts first, then directories are created and files are copied in
+// parallel.
+func CopyPathWithOptions(src string, dst string, opts CopyOptions) (CopyResult, error) {
+	if opts.Mode == "" {
+		opts.Mode = CopyModeReflink
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictOverwrite
+	}
+
+	dirs, jobs, err := planCopy(src, dst, opts)
+	if err != nil {
+		return CopyResult{}, err
+	}
+
+	jobs, conflicts, err := resolveConflicts(jobs, opts)
+	result := CopyResult{Conflicts: conflicts}
+	if err != nil {
+		return result, err
+	}
+
+	for _, dir := range dirs {
+		// Keep directories writable until their contents are copied
+		if err := os.MkdirAll(dir.dst, dir.mode.Perm()|0700); err != nil {
+			return result, err
+		}
+	}
+
+	var totalBytes int64
+	for _, job := range jobs {
+		totalBytes += job.size
+	}
+
+	progress := newCopyProgress(len(jobs), totalBytes, opts.Progress)
+	err = runCopyJobs(jobs, opts, progress)
+	progress.finish()
+	result.Files = int(progress.files.Load())
+	if err != nil {
+		return result, err
+	}
+
+	// Set directory modes and times last, deepest first, since copying into
+	// a directory changes its modification time
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := preserveAttributes(dirs[i]); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// planCopy walks the source, returning the directories to create and the
+// files to copy, with excluded and ignored paths left out
+func planCopy(src, dst string, opts CopyOptions) ([]copyJob, []copyJob, error) {
+	rootInfo, err := os.Lstat(src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Exclude and ignore rules match relative to the copied directory, or
+	// the parent of a single copied file
+	matchBase := src
+	if !rootInfo.IsDir() {
+		matchBase = filepath.Dir(src)
+	}
+
+	var ignored map[string]bool
+	if opts.Gitignore {
+		ignored, err = gitIgnoredPaths(matchBase)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var dirs, jobs []copyJob
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		matchPath, err := filepath.Rel(matchBase, path)
+		if err != nil {
+			return err
+		}
+
+		if matchPath != "." && (excludedPath(matchPath, opts.Exclude) || ignoredPath(matchPath, info.IsDir(), ignored)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		job := copyJob{
+			src:     path,
+			dst:     filepath.Join(dst, relPath),
+			mode:    info.Mode(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+
+		switch {
+		case info.IsDir():
+			dirs = append(dirs, job)
+		case info.Mode()&os.ModeSymlink != 0:
+			job.link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			job.size = 0
+			jobs = append(jobs, job)
+		case info.Mode().IsRegular():
+			jobs = append(jobs, job)
+		default:
+			// Sockets, devices and pipes can't be copied meaningfully
+		}
+		return nil
+	})
+
+	return dirs, jobs, err
+}
+
+// excludedPath reports whether a relative path or its base name matches any exclude glob
+func excludedPath(relPath string, patterns []string) bool {
+	slashPath := filepath.ToSlash(relPath)
+	base := filepath.Base(relPath)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if matched, _ := path.Match(pattern, slashPath); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredPath reports whether a relative path is in the set of git-ignored paths
+func ignoredPath(relPath string, isDir bool, ignored map[string]bool) bool {
+	if ignored == nil {
+		return false
+	}
+	slashPath := filepath.ToSlash(relPath)
+	if isDir {
+		return ignored[slashPath+"/"]
+	}
+	return ignored[slashPath]
+}
+
+// gitIgnoredPaths lists untracked paths git ignores under a directory,
+// relative to it. Fully ignored directories end with a slash.
+func gitIgnoredPaths(dir string) (map[string]bool, error) {
+	output, err := RunCommand("git", "-C", dir, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list git-ignored files in %s: %w", dir, err)
+	}
+
+	ignored := make(map[string]bool)
+	for _, line := range splitLines(output) {
+		ignored[line] = true
+	}
+	return ignored, nil
+}
+
+// resolveConflicts applies the conflict policy to files whose destination
+// already exists with different content. Identical files are dropped.
+func resolveConflicts(jobs []copyJob, opts CopyOptions) ([]copyJob, []string, error) {
+	var remaining []copyJob
+	var conflicts []string
+
+	// Backups must not land on a file being copied or another backup
+	taken := make(map[string]bool)
+	for _, job := range jobs {
+		taken[job.dst] = true
+	}
+
+	for _, job := range jobs {
+		identical, exists, err := sameAsDestination(job, opts.Mode)
+		if err != nil {
+			return nil, nil, err
+		}
+		if identical {
+			continue
+		}
+		if !exists {
+			remaining = append(remaining, job)
+			continue
+		}
+
+		conflicts = append(conflicts, job.dst)
+		switch opts.Conflict {
+		case ConflictSkip:
+			continue
+		case ConflictBackup:
+			job.backup = backupPath(job.dst, taken)
+			taken[job.backup] = true
+		}
+		remaining = append(remaining, job)
+	}
+
+	if opts.Conflict == ConflictFail && len(conflicts) > 0 {
+		return nil, conflicts, fmt.Errorf("%d file(s) already exist with different content:\n  %s",
+			len(conflicts), strings.Join(conflicts, "\n  "))
+	}
+
+	return remaining, conflicts, nil
+}
+
+// backupPath returns the first of path.bak, path.bak.1, path.bak.2, ... that
+// doesn't exist and isn't taken
+func backupPath(path string, taken map[string]bool) string {
+	candidate := path + backupSuffix
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) && !taken[candidate] {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s%s.%d", path, backupSuffix, i)
+	}
+}
+
+// sameAsDestination reports whether a job's destination already matches its
+// source, and whether the destination exists at all
+func sameAsDestination(job copyJob, mode CopyMode) (bool, bool, error) {
+	dstInfo, err := os.Lstat(job.dst)
+	if os.IsNotExist(err) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	if job.link != "" {
+		if dstInfo.Mode()&os.ModeSymlink == 0 {
+			return false, true, nil
+		}
+		target, err := os.Readlink(job.dst)
+		return err == nil && target == job.link, true, nil
+	}
+
+	if !dstInfo.Mode().IsRegular() || dstInfo.Size() != job.size {
+		return false, true, nil
+	}
+
+	srcInfo, err := os.Stat(job.src)
+	if err != nil {
+		return false, true, err
+	}
+	if os.SameFile(srcInfo, dstInfo) {
+		return true, true, nil
+	}
+	// A hardlink copy should end up as the same file, not an equal one
+	if mode == CopyModeHardlink {
+		return false, true, nil
+	}
+
+	same, err := sameContent(job.src, job.dst)
+	return same, true, err
+}
+
+// sameContent compares two files byte by byte
+func sameContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if na != nb || !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
+
+// runCopyJobs copies files with a pool of workers, stopping at the first error
+func runCopyJobs(jobs []copyJob, opts CopyOptions, progress *copyProgress) error {
+	queue := make(chan copyJob)
+	var firstErr error
+	var errOnce sync.Once
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+
+	for range min(opts.Workers, max(len(jobs), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if err := copyJobWithBackup(job, opts.Mode); err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("%s: %w", job.src, err)
+						failed.Store(true)
+					})
+					continue
+				}
+				progress.add(job.size)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		if failed.Load() {
+			break
+		}
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	return firstErr
+}
+
+// copyJobWithBackup copies a job, first moving the existing destination to
+// its backup if it has one. The backup is moved back if the copy fails.
+func copyJobWithBackup(job copyJob, mode CopyMode) error {
+	if job.backup == "" {
+		return copyEntry(job, mode)
+	}
+
+	if err := os.Rename(job.dst, job.backup); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", job.dst, err)
+	}
+	if err := copyEntry(job, mode); err != nil {
+		_ = os.Remove(job.dst)
+		if restoreErr := os.Rename(job.backup, job.dst); restoreErr != nil {
+			return fmt.Errorf("%w (backup left at %s: %v)", err, job.backup, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// copyEntry replaces the destination with a copy of a file or symlink.
+// The destination is removed first so that writes never go through an
+// existing symlink or hardlink into another file.
+func copyEntry(job copyJob, mode CopyMode) error {
+	if err := os.Remove(job.dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if job.link != "" {
+		return os.Symlink(job.link, job.dst)
+	}
+
+	linked, err := copyFile(job.src, job.dst, job.mode, mode)
+	if err != nil {
+		return err
+	}
+
+	// Hardlinks share the source's attributes already
+	if linked {
+		return nil
+	}
+	return preserveAttributes(job)
+}
+
+// preserveAttributes sets a copied file or directory's permissions and
+// modification time to match its source
+func preserveAttributes(job copyJob) error {
+	if err := os.Chmod(job.dst, job.mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(job.dst, job.modTime, job.modTime)
+}
+
+// copyFile copies a single file with the given mode, falling back to a plain
+// copy when reflinks or hardlinks aren't possible. Reports whether the
+// destination was hardlinked to the source.
+func copyFile(src, dst string, perm os.FileMode, mode CopyMode) (bool, error) {
+	switch mode {
+	case CopyModeHardlink:
+		if err := os.Link(src, dst); err == nil {
+			return true, nil
+		}
+	case CopyModeReflink:
+		if err := reflinkFileWithMode(src, dst, perm); err == nil {
+			return false, nil
+		}
+	}
+
+	return false, copyFileWithMode(src, dst, perm)
+}
+
+// reflinkFileWithMode clones a file's data into the destination
+func reflinkFileWithMode(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	return reflinkFile(in, out)
+}
+
+func copyFileWithMode(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// progressMinFiles is the number of files below which copies finish too
+// quickly to need a progress indicator
+const progressMinFiles = 100
+
+// copyProgress prints a progress indicator while files are copied
+type copyProgress struct {
+	totalFiles int
+	totalBytes int64
+	files      atomic.Int64
+	bytes      atomic.Int64
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// newCopyProgress starts a progress indicator, which is a no-op when disabled
+func newCopyProgress(totalFiles int, totalBytes int64, enabled bool) *copyProgress {
+	p := &copyProgress{totalFiles: totalFiles, totalBytes: totalBytes}
+	if !enabled || totalFiles < progressMinFiles {
+		return p
+	}
+
+	p.done = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.print()
+			case <-p.done:
+				p.print()
+				fmt.Fprintln(os.Stderr)
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *copyProgress) add(size int64) {
+	p.files.Add(1)
+	p.bytes.Add(size)
+}
+
+func (p *copyProgress) print() {
+	fmt.Fprintf(os.Stderr, "\rCopying %d/%d files (%s/%s)",
+		p.files.Load(), p.totalFiles, formatBytes(p.bytes.Load()), formatBytes(p.totalBytes))
+}
+
+func (p *copyProgress) finish() {
+	if p.done != nil {
+		close(p.done)
+		p.wg.Wait()
+	}
+}
+
+// formatBytes formats a byte count for display
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
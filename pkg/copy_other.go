@@ -0,0 +1,10 @@
+//go:build !linux
+
+package pkg
+
+import "os"
+
+// reflinkFile is unsupported outside Linux, so copies fall back to a plain copy
+func reflinkFile(src, dst *os.File) error {
+	return errReflinkUnsupported
+}
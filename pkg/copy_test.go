@@ -0,0 +1,306 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyPathWithOptions_Modes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	for i := range 20 {
+		dir := filepath.Join(src, fmt.Sprintf("dir%d", i%3))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		writeTestFile(t, filepath.Join(dir, fmt.Sprintf("file%d.txt", i)), fmt.Sprintf("content %d\n", i))
+	}
+
+	for _, mode := range []CopyMode{CopyModeReflink, CopyModeHardlink, CopyModeCopy} {
+		t.Run(string(mode), func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "dst")
+			if _, err := CopyPathWithOptions(src, dst, CopyOptions{Mode: mode, Workers: 4}); err != nil {
+				t.Fatalf("CopyPathWithOptions failed: %v", err)
+			}
+
+			for i := range 20 {
+				rel := filepath.Join(fmt.Sprintf("dir%d", i%3), fmt.Sprintf("file%d.txt", i))
+				content, err := os.ReadFile(filepath.Join(dst, rel))
+				if err != nil {
+					t.Fatalf("missing %s: %v", rel, err)
+				}
+				if string(content) != fmt.Sprintf("content %d\n", i) {
+					t.Fatalf("unexpected content in %s: %q", rel, content)
+				}
+
+				srcInfo, _ := os.Stat(filepath.Join(src, rel))
+				dstInfo, _ := os.Stat(filepath.Join(dst, rel))
+				if linked := os.SameFile(srcInfo, dstInfo); linked != (mode == CopyModeHardlink) {
+					t.Fatalf("%s: expected hardlinked=%v", rel, mode == CopyModeHardlink)
+				}
+			}
+		})
+	}
+}
+
+func TestParseCopyMode(t *testing.T) {
+	if _, err := ParseCopyMode("reflink"); err != nil {
+		t.Fatalf("expected reflink to be valid: %v", err)
+	}
+	if _, err := ParseCopyMode("teleport"); err == nil {
+		t.Fatalf("expected unknown mode to fail")
+	}
+}
+
+func TestCopyPathWithOptions_Truncates(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	writeTestFile(t, src, "short\n")
+	writeTestFile(t, dst, "a much longer existing file\n")
+
+	for _, mode := range []CopyMode{CopyModeReflink, CopyModeCopy} {
+		if _, err := CopyPathWithOptions(src, dst, CopyOptions{Mode: mode}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+		content, _ := os.ReadFile(dst)
+		if string(content) != "short\n" {
+			t.Fatalf("%s: expected truncated content, got %q", mode, content)
+		}
+	}
+}
+
+func TestCopyPathWithOptions_PreservesSymlinksModesAndTimes(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(src, "run.sh"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(src, "run.sh"), 0750); err != nil {
+		t.Fatalf("chmod failed: %v", err)
+	}
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(src, "run.sh"), modTime, modTime); err != nil {
+		t.Fatalf("chtimes failed: %v", err)
+	}
+	if err := os.Symlink("run.sh", filepath.Join(src, "link.sh")); err != nil {
+		t.Fatalf("symlink failed: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if _, err := CopyPathWithOptions(src, dst, CopyOptions{Mode: CopyModeCopy}); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	if err != nil {
+		t.Fatalf("missing run.sh: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Fatalf("expected mode 0750, got %o", info.Mode().Perm())
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Fatalf("expected mtime %v, got %v", modTime, info.ModTime())
+	}
+
+	target, err := os.Readlink(filepath.Join(dst, "link.sh"))
+	if err != nil || target != "run.sh" {
+		t.Fatalf("expected symlink to run.sh, got %q (%v)", target, err)
+	}
+}
+
+func TestCopyPathWithOptions_DoesNotWriteThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	other := filepath.Join(dir, "other.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	writeTestFile(t, src, "new\n")
+	writeTestFile(t, other, "other\n")
+	if err := os.Symlink(other, dst); err != nil {
+		t.Fatalf("symlink failed: %v", err)
+	}
+
+	if _, err := CopyPathWithOptions(src, dst, CopyOptions{}); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(other)
+	if string(content) != "other\n" {
+		t.Fatalf("copy wrote through the destination symlink: %q", content)
+	}
+	content, _ = os.ReadFile(dst)
+	if string(content) != "new\n" {
+		t.Fatalf("expected copied content, got %q", content)
+	}
+}
+
+func TestCopyPathWithOptions_Exclude(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	for _, file := range []string{"keep.txt", "debug.log", "cache/data", "sub/keep.txt", "sub/trace.log"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(src, file)), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		writeTestFile(t, filepath.Join(src, file), file)
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	opts := CopyOptions{Exclude: []string{"*.log", "cache/"}}
+	if _, err := CopyPathWithOptions(src, dst, opts); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+
+	for _, file := range []string{"keep.txt", "sub/keep.txt"} {
+		if _, err := os.Stat(filepath.Join(dst, file)); err != nil {
+			t.Fatalf("expected %s to be copied", file)
+		}
+	}
+	for _, file := range []string{"debug.log", "sub/trace.log", "cache"} {
+		if _, err := os.Lstat(filepath.Join(dst, file)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be excluded", file)
+		}
+	}
+}
+
+func TestCopyPathWithOptions_Gitignore(t *testing.T) {
+	repo := setupTestRepo(t)
+	src := filepath.Join(repo.MainWorktree.Path, "config")
+	if err := os.MkdirAll(filepath.Join(src, "tmp"), 0755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo.MainWorktree.Path, ".gitignore"), "*.secret\ntmp/\n")
+	writeTestFile(t, filepath.Join(src, "app.yml"), "app")
+	writeTestFile(t, filepath.Join(src, "key.secret"), "secret")
+	writeTestFile(t, filepath.Join(src, "tmp", "scratch"), "scratch")
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if _, err := CopyPathWithOptions(src, dst, CopyOptions{Gitignore: true}); err != nil {
+		t.Fatalf("copy failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "app.yml")); err != nil {
+		t.Fatalf("expected app.yml to be copied")
+	}
+	for _, file := range []string{"key.secret", "tmp"} {
+		if _, err := os.Lstat(filepath.Join(dst, file)); !os.IsNotExist(err) {
+			t.Fatalf("expected ignored %s to be skipped", file)
+		}
+	}
+}
+
+func TestCopyPathWithOptions_ConflictPolicies(t *testing.T) {
+	setup := func(t *testing.T) (string, string) {
+		src := filepath.Join(t.TempDir(), "src")
+		dst := filepath.Join(t.TempDir(), "dst")
+		for _, dir := range []string{src, dst} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("failed to create dir: %v", err)
+			}
+		}
+		writeTestFile(t, filepath.Join(src, "a.txt"), "source a\n")
+		writeTestFile(t, filepath.Join(src, "b.txt"), "same\n")
+		writeTestFile(t, filepath.Join(src, "c.txt"), "source c\n")
+		writeTestFile(t, filepath.Join(dst, "a.txt"), "local a\n")
+		writeTestFile(t, filepath.Join(dst, "b.txt"), "same\n")
+		return src, dst
+	}
+
+	read := func(t *testing.T, path string) string {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return ""
+		}
+		return string(content)
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		src, dst := setup(t)
+		result, err := CopyPathWithOptions(src, dst, CopyOptions{Conflict: ConflictFail})
+		if err == nil {
+			t.Fatalf("expected conflict error")
+		}
+		if len(result.Conflicts) != 1 || filepath.Base(result.Conflicts[0]) != "a.txt" {
+			t.Fatalf("expected a.txt conflict, got %v", result.Conflicts)
+		}
+		if read(t, filepath.Join(dst, "c.txt")) != "" {
+			t.Fatalf("expected nothing to be copied on conflict")
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		src, dst := setup(t)
+		if _, err := CopyPathWithOptions(src, dst, CopyOptions{Conflict: ConflictOverwrite}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt")); got != "source a\n" {
+			t.Fatalf("expected a.txt overwritten, got %q", got)
+		}
+	})
+
+	t.Run("skip-existing", func(t *testing.T) {
+		src, dst := setup(t)
+		result, err := CopyPathWithOptions(src, dst, CopyOptions{Conflict: ConflictSkip})
+		if err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt")); got != "local a\n" {
+			t.Fatalf("expected a.txt kept, got %q", got)
+		}
+		if got := read(t, filepath.Join(dst, "c.txt")); got != "source c\n" {
+			t.Fatalf("expected c.txt copied, got %q", got)
+		}
+		if result.Files != 1 {
+			t.Fatalf("expected 1 file copied, got %d", result.Files)
+		}
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		src, dst := setup(t)
+		if _, err := CopyPathWithOptions(src, dst, CopyOptions{Conflict: ConflictBackup}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt")); got != "source a\n" {
+			t.Fatalf("expected a.txt replaced, got %q", got)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt.bak")); got != "local a\n" {
+			t.Fatalf("expected backup of a.txt, got %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(dst, "b.txt.bak")); !os.IsNotExist(err) {
+			t.Fatalf("identical file should not be backed up")
+		}
+	})
+
+	t.Run("backup keeps existing backups", func(t *testing.T) {
+		src, dst := setup(t)
+		writeTestFile(t, filepath.Join(dst, "a.txt.bak"), "older a\n")
+		if _, err := CopyPathWithOptions(src, dst, CopyOptions{Conflict: ConflictBackup}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt.bak")); got != "older a\n" {
+			t.Fatalf("expected existing backup kept, got %q", got)
+		}
+		if got := read(t, filepath.Join(dst, "a.txt.bak.1")); got != "local a\n" {
+			t.Fatalf("expected new backup of a.txt, got %q", got)
+		}
+	})
+
+	t.Run("backup restored on failure", func(t *testing.T) {
+		_, dst := setup(t)
+		job := copyJob{
+			src:    filepath.Join(dst, "missing.txt"),
+			dst:    filepath.Join(dst, "a.txt"),
+			mode:   0644,
+			backup: filepath.Join(dst, "a.txt.bak"),
+		}
+		if err := copyJobWithBackup(job, CopyModeCopy); err == nil {
+			t.Fatalf("expected copy of a missing source to fail")
+		}
+		if got := read(t, filepath.Join(dst, "a.txt")); got != "local a\n" {
+			t.Fatalf("expected a.txt restored, got %q", got)
+		}
+		if _, err := os.Stat(job.backup); !os.IsNotExist(err) {
+			t.Fatalf("expected no backup left behind")
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DiffFormat selects how PrintWorktreeDiff shows differences
+type DiffFormat string
+
+const (
+	DiffUnified  DiffFormat = "unified"
+	DiffStat     DiffFormat = "stat"
+	DiffNameOnly DiffFormat = "name-only"
+)
+
+// DiffEntry is a file that differs between two worktrees
+type DiffEntry struct {
+	File   string // Path relative to the worktree root
+	Status string // added, deleted or modified, going from one worktree to the other
+}
+
+// DiffWorktrees compares a file or directory in the working directories of
+// two worktrees, including untracked and skipped files. With gitignore set,
+// files ignored by git on either side are left out.
+func (r *Repo) DiffWorktrees(from, to *Worktree, path string, gitignore bool) ([]DiffEntry, error) {
+	fromFiles, err := listDiffFiles(from.Path, path, gitignore)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, err := listDiffFiles(to.Path, path, gitignore)
+	if err != nil {
+		return nil, err
+	}
+	if len(fromFiles) == 0 && len(toFiles) == 0 {
+		if _, err := os.Lstat(filepath.Join(from.Path, path)); os.IsNotExist(err) {
+			if _, err := os.Lstat(filepath.Join(to.Path, path)); os.IsNotExist(err) {
+				return nil, fmt.Errorf("path does not exist in %s or %s: %s", from.Name, to.Name, path)
+			}
+		}
+	}
+
+	var entries []DiffEntry
+	for file := range fromFiles {
+		if !toFiles[file] {
+			entries = append(entries, DiffEntry{File: file, Status: "deleted"})
+			continue
+		}
+		if !sameFileContent(filepath.Join(from.Path, file), filepath.Join(to.Path, file)) {
+			entries = append(entries, DiffEntry{File: file, Status: "modified"})
+		}
+	}
+	for file := range toFiles {
+		if !fromFiles[file] {
+			entries = append(entries, DiffEntry{File: file, Status: "added"})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].File < entries[j].File
+	})
+	return entries, nil
+}
+
+// listDiffFiles lists files under a path in a worktree, relative to the worktree root
+func listDiffFiles(root, path string, gitignore bool) (map[string]bool, error) {
+	fullPath := filepath.Join(root, path)
+	if _, err := os.Lstat(fullPath); os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+
+	_, jobs, err := planCopy(fullPath, "", CopyOptions{Exclude: []string{".git"}, Gitignore: gitignore})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool)
+	for _, job := range jobs {
+		rel, err := filepath.Rel(root, job.src)
+		if err != nil {
+			return nil, err
+		}
+		files[rel] = true
+	}
+	return files, nil
+}
+
+// sameFileContent reports whether two paths resolve to the same file or
+// identical content. Unreadable paths, such as dangling symlinks, differ.
+func sameFileContent(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if os.SameFile(infoA, infoB) {
+		return true
+	}
+	if infoA.Size() != infoB.Size() {
+		return false
+	}
+	same, err := sameContent(a, b)
+	return err == nil && same
+}
+
+// PrintWorktreeDiff displays the differences between two worktrees
+func (r *Repo) PrintWorktreeDiff(from, to *Worktree, entries []DiffEntry, format DiffFormat) error {
+	if format == DiffNameOnly {
+		for _, entry := range entries {
+			fmt.Println(entry.File)
+		}
+		return nil
+	}
+
+	var stats []diffStat
+	for _, entry := range entries {
+		fromPath, toPath := filepath.Join(from.Path, entry.File), filepath.Join(to.Path, entry.File)
+		switch entry.Status {
+		case "added":
+			fromPath = os.DevNull
+		case "deleted":
+			toPath = os.DevNull
+		}
+
+		if format == DiffStat {
+			stat, err := r.numstat(fromPath, toPath)
+			if err != nil {
+				return err
+			}
+			stat.file = entry.File
+			stats = append(stats, stat)
+			continue
+		}
+
+		diff, err := r.diffPaths(fromPath, toPath)
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+	}
+
+	if format == DiffStat {
+		printDiffStat(stats)
+	}
+	return nil
+}
+
+// diffStat is the number of lines changed in a file, binary files have no counts
+type diffStat struct {
+	file       string
+	insertions int
+	deletions  int
+	binary     bool
+}
+
+// numstat counts the lines changed between two paths
+func (r *Repo) numstat(from, to string) (diffStat, error) {
+	output, err := r.diffPaths(from, to, "--numstat")
+	if err != nil {
+		return diffStat{}, err
+	}
+
+	// Format: <insertions>\t<deletions>\t<path>, with - for binary files
+	fields := strings.Fields(output)
+	if len(fields) < 2 || fields[0] == "-" {
+		return diffStat{binary: len(fields) >= 2}, nil
+	}
+
+	var stat diffStat
+	stat.insertions, _ = strconv.Atoi(fields[0])
+	stat.deletions, _ = strconv.Atoi(fields[1])
+	return stat, nil
+}
+
+// printDiffStat displays per-file change counts in the style of git diff --stat
+func printDiffStat(stats []diffStat) {
+	const barWidth = 40
+
+	nameWidth, maxChanges := 0, 0
+	for _, stat := range stats {
+		nameWidth = max(nameWidth, len(stat.file))
+		maxChanges = max(maxChanges, stat.insertions+stat.deletions)
+	}
+
+	totalInsertions, totalDeletions := 0, 0
+	for _, stat := range stats {
+		if stat.binary {
+			fmt.Printf(" %-*s | Bin\n", nameWidth, stat.file)
+			continue
+		}
+
+		changes := stat.insertions + stat.deletions
+		plus, minus := stat.insertions, stat.deletions
+		if maxChanges > barWidth {
+			plus = stat.insertions * barWidth / maxChanges
+			minus = stat.deletions * barWidth / maxChanges
+		}
+		fmt.Printf(" %-*s | %d %s%s\n", nameWidth, stat.file, changes,
+			color.GreenString(strings.Repeat("+", plus)), color.RedString(strings.Repeat("-", minus)))
+
+		totalInsertions += stat.insertions
+		totalDeletions += stat.deletions
+	}
+
+	fmt.Printf(" %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)\n", len(stats), totalInsertions, totalDeletions)
+}
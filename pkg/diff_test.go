@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffWorktrees_IncludesUntrackedFiles(t *testing.T) {
+	repo := setupTestRepo(t)
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	main := repo.MainWorktree
+	feature := repo.FindWorktreeByName("feature")
+
+	writeTestFile(t, filepath.Join(main.Path, ".gitignore"), "*.log\n")
+	writeTestFile(t, filepath.Join(feature.Path, ".gitignore"), "*.log\n")
+	writeTestFile(t, filepath.Join(main.Path, ".env"), "A=1\n")
+	writeTestFile(t, filepath.Join(feature.Path, ".env"), "A=2\n")
+	writeTestFile(t, filepath.Join(main.Path, "old.txt"), "old\n")
+	writeTestFile(t, filepath.Join(feature.Path, "new.txt"), "new\n")
+	writeTestFile(t, filepath.Join(feature.Path, "debug.log"), "log\n")
+
+	entries, err := repo.DiffWorktrees(main, feature, ".", false)
+	if err != nil {
+		t.Fatalf("DiffWorktrees failed: %v", err)
+	}
+
+	want := map[string]string{
+		".env":      "modified",
+		"debug.log": "added",
+		"new.txt":   "added",
+		"old.txt":   "deleted",
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for _, entry := range entries {
+		if want[entry.File] != entry.Status {
+			t.Fatalf("unexpected entry %+v", entry)
+		}
+	}
+
+	// Ignored files can be left out, and paths narrow the comparison
+	entries, err = repo.DiffWorktrees(main, feature, ".", true)
+	if err != nil {
+		t.Fatalf("DiffWorktrees failed: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.File == "debug.log" {
+			t.Fatalf("expected ignored debug.log to be left out")
+		}
+	}
+
+	entries, err = repo.DiffWorktrees(main, feature, ".env", false)
+	if err != nil {
+		t.Fatalf("DiffWorktrees failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].File != ".env" {
+		t.Fatalf("expected only .env, got %+v", entries)
+	}
+
+	stat, err := repo.numstat(filepath.Join(main.Path, ".env"), filepath.Join(feature.Path, ".env"))
+	if err != nil {
+		t.Fatalf("numstat failed: %v", err)
+	}
+	if stat.insertions != 1 || stat.deletions != 1 {
+		t.Fatalf("expected 1 insertion and 1 deletion, got %+v", stat)
+	}
+
+	if _, err := repo.DiffWorktrees(main, feature, "missing", false); err == nil {
+		t.Fatalf("expected error for a path missing on both sides")
+	}
+}
@@ -0,0 +1,406 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// IssueKind identifies the type of problem found by Diagnose
+type IssueKind int
+
+const (
+	IssueBrokenSkipLink IssueKind = iota
+	IssueMissingWorktree
+	IssueStrayDirectory
+	IssueMissingCopySource
+	IssueMissingExcludeFile
+	IssueInterruptedSkip
+)
+
+// Issue describes a single problem found by Diagnose
+type Issue struct {
+	Kind     IssueKind
+	Worktree *Worktree // Affected worktree, nil if not worktree-specific
+	Path     string    // Affected file, directory or config entry
+	Message  string    // Human readable explanation
+}
+
+// String returns the display string for an issue
+func (i Issue) String() string {
+	if i.Worktree != nil {
+		return fmt.Sprintf("%s: %s", i.Worktree.Name, i.Message)
+	}
+	return i.Message
+}
+
+// Diagnose checks the repository for state that has drifted from what wrk expects
+func (r *Repo) Diagnose() ([]Issue, error) {
+	var issues []Issue
+
+	checks := []func() ([]Issue, error){
+		r.checkMissingWorktrees,
+		r.checkStrayDirectories,
+		r.checkSkipLinks,
+		r.checkCopySources,
+		r.checkExcludeFile,
+		r.checkSkipJournal,
+	}
+
+	for _, check := range checks {
+		found, err := check()
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+
+	return issues, nil
+}
+
+// checkMissingWorktrees finds worktrees known to git whose directories no longer exist
+func (r *Repo) checkMissingWorktrees() ([]Issue, error) {
+	var issues []Issue
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			issues = append(issues, Issue{
+				Kind:     IssueMissingWorktree,
+				Worktree: wt,
+				Path:     wt.Path,
+				Message:  fmt.Sprintf("directory %s no longer exists but git still tracks the worktree", wt.Path),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// checkStrayDirectories finds directories in WorktreesDir that git doesn't know about
+func (r *Repo) checkStrayDirectories() ([]Issue, error) {
+	entries, err := os.ReadDir(r.WorktreesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read worktrees directory: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, wt := range r.Worktrees {
+		known[filepath.Clean(wt.Path)] = true
+	}
+
+	var issues []Issue
+	for _, entry := range entries {
+		// Hidden entries hold wrk's own state (config, metadata)
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(r.WorktreesDir, entry.Name())
+		if known[path] || r.containsWorktree(path) {
+			continue
+		}
+
+		issues = append(issues, Issue{
+			Kind:    IssueStrayDirectory,
+			Path:    path,
+			Message: fmt.Sprintf("directory %s is not a git worktree", path),
+		})
+	}
+	return issues, nil
+}
+
+// containsWorktree reports whether a directory is a parent of a known worktree,
+// as happens with slash-separated worktree names like feature/auth
+func (r *Repo) containsWorktree(dir string) bool {
+	for _, wt := range r.Worktrees {
+		if strings.HasPrefix(wt.Path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSkipLinks finds skipped files in non-main worktrees that are no longer
+// tied to an existing file in the main worktree
+func (r *Repo) checkSkipLinks() ([]Issue, error) {
+	var issues []Issue
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		if r.IsMainWorktree(wt) {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			// Reported as a missing worktree
+			continue
+		}
+
+		wtIssues, err := r.skipLinkIssues(wt)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, wtIssues...)
+	}
+	return issues, nil
+}
+
+// skipLinkIssues finds skipped files in a worktree whose symlink, hardlink or
+// copy no longer matches an existing file in the main worktree
+func (r *Repo) skipLinkIssues(wt *Worktree) ([]Issue, error) {
+	skipped, err := r.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		return nil, err
+	}
+	mainSkipped, err := r.getSkippedFilesInWorktree(r.MainWorktree)
+	if err != nil {
+		return nil, err
+	}
+	// Files outside a sparse checkout also carry the skip-worktree bit
+	sparse := r.sparseEnabled(wt)
+
+	var issues []Issue
+	for file := range skipped {
+		wtFilePath := filepath.Join(wt.Path, file)
+		mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+		strategy := r.SkipStrategyFor(file)
+
+		info, err := os.Lstat(wtFilePath)
+		if err != nil {
+			if os.IsNotExist(err) && strategy != SkipSymlink && mainSkipped[file] && !sparse {
+				issues = append(issues, Issue{
+					Kind:     IssueBrokenSkipLink,
+					Worktree: wt,
+					Path:     file,
+					Message:  fmt.Sprintf("skipped file %s is missing instead of a %s of %s", file, strategy, mainFilePath),
+				})
+			}
+			continue
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			if strategy == SkipSymlink {
+				continue
+			}
+			mainInfo, err := os.Stat(mainFilePath)
+			if os.IsNotExist(err) {
+				issues = append(issues, Issue{
+					Kind:     IssueBrokenSkipLink,
+					Worktree: wt,
+					Path:     file,
+					Message:  fmt.Sprintf("skipped file %s is a %s of %s, which no longer exists in main", file, strategy, mainFilePath),
+				})
+			} else if err == nil && strategy == SkipHardlink && !os.SameFile(info, mainInfo) {
+				issues = append(issues, Issue{
+					Kind:     IssueBrokenSkipLink,
+					Worktree: wt,
+					Path:     file,
+					Message:  fmt.Sprintf("skipped file %s is no longer hard-linked to %s", file, mainFilePath),
+				})
+			}
+			continue
+		}
+
+		target, err := os.Readlink(wtFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read symlink %s: %w", wtFilePath, err)
+		}
+
+		if _, err := os.Stat(mainFilePath); os.IsNotExist(err) {
+			issues = append(issues, Issue{
+				Kind:     IssueBrokenSkipLink,
+				Worktree: wt,
+				Path:     file,
+				Message:  fmt.Sprintf("skipped file %s links to %s, which no longer exists in main", file, mainFilePath),
+			})
+		} else if target != mainFilePath {
+			issues = append(issues, Issue{
+				Kind:     IssueBrokenSkipLink,
+				Worktree: wt,
+				Path:     file,
+				Message:  fmt.Sprintf("skipped file %s links to %s instead of %s", file, target, mainFilePath),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// checkCopySources finds always-copy entries whose source is missing in the main worktree
+func (r *Repo) checkCopySources() ([]Issue, error) {
+	if r.Config == nil {
+		return nil, nil
+	}
+
+	var issues []Issue
+	for _, path := range r.Config.Copy {
+		if _, err := os.Stat(filepath.Join(r.MainWorktree.Path, path)); os.IsNotExist(err) {
+			issues = append(issues, Issue{
+				Kind:    IssueMissingCopySource,
+				Path:    path,
+				Message: fmt.Sprintf("always-copy path %s does not exist in the main worktree", path),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// checkExcludeFile verifies .git/info/exclude exists
+func (r *Repo) checkExcludeFile() ([]Issue, error) {
+	excludePath := r.excludePath()
+	if _, err := os.Stat(excludePath); os.IsNotExist(err) {
+		return []Issue{{
+			Kind:    IssueMissingExcludeFile,
+			Path:    excludePath,
+			Message: fmt.Sprintf("exclude file %s is missing, so 'wrk exclude' cannot add patterns", excludePath),
+		}}, nil
+	}
+	return nil, nil
+}
+
+// checkSkipJournal finds a skip or unskip that was interrupted part way
+func (r *Repo) checkSkipJournal() ([]Issue, error) {
+	if !r.HasSkipJournal() {
+		return nil, nil
+	}
+	return []Issue{{
+		Kind:    IssueInterruptedSkip,
+		Path:    r.journalPath(),
+		Message: "a skip or unskip was interrupted, leaving worktrees in different states",
+	}}, nil
+}
+
+// errStrayKept is returned by RepairIssue for a stray directory left in place
+var errStrayKept = errors.New("left in place (use --remove-stray to delete it)")
+
+// RepairIssue applies the fix for a single issue. Stray directories are only
+// deleted when removeStray is set, since they may contain work.
+func (r *Repo) RepairIssue(issue Issue, removeStray bool) error {
+	switch issue.Kind {
+	case IssueMissingWorktree:
+		_, err := r.RunGitCommand(r.MainWorktree, "worktree", "prune")
+		if err != nil {
+			return fmt.Errorf("failed to prune worktrees: %w", err)
+		}
+
+	case IssueStrayDirectory:
+		// A directory with a .git file may be a worktree git lost track of
+		if _, err := os.Stat(filepath.Join(issue.Path, ".git")); err == nil {
+			if _, err := r.RunGitCommand(r.MainWorktree, "worktree", "repair", issue.Path); err == nil {
+				return nil
+			}
+		}
+		if !removeStray {
+			return errStrayKept
+		}
+		if err := os.RemoveAll(issue.Path); err != nil {
+			return fmt.Errorf("failed to remove directory: %w", err)
+		}
+
+	case IssueBrokenSkipLink:
+		return r.relinkSkippedFile(issue.Worktree, issue.Path)
+
+	case IssueMissingCopySource:
+		return r.RemoveAlwaysCopy(issue.Path)
+
+	case IssueInterruptedSkip:
+		// Rolling back is always safe, resuming can be done with 'wrk skip --resume'
+		if _, err := r.UndoSkipJournal(); err != nil {
+			return err
+		}
+
+	case IssueMissingExcludeFile:
+		if err := os.MkdirAll(filepath.Dir(issue.Path), 0755); err != nil {
+			return fmt.Errorf("failed to create info directory: %w", err)
+		}
+		if err := os.WriteFile(issue.Path, []byte("# git ls-files --others --exclude-from=.git/info/exclude\n"), 0644); err != nil {
+			return fmt.Errorf("failed to create exclude file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// relinkSkippedFile ties a skipped file back to the main worktree using its
+// skip strategy, or restores the worktree's own version if the file is gone
+// from main
+func (r *Repo) relinkSkippedFile(wt *Worktree, file string) error {
+	wtFilePath := filepath.Join(wt.Path, file)
+	mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+
+	if err := os.Remove(wtFilePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+
+	if _, err := os.Stat(mainFilePath); err == nil {
+		return placeSkippedFile(mainFilePath, wtFilePath, r.SkipStrategyFor(file))
+	}
+
+	// Main no longer has the file, so stop skipping it here and restore the branch version
+	if _, err := r.RunGitCommand(wt, "update-index", "--no-skip-worktree", file); err != nil {
+		return fmt.Errorf("failed to unskip: %w", err)
+	}
+	if _, err := r.RunGitCommand(wt, "checkout", "--", file); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+	return nil
+}
+
+// Repair fixes all diagnosed issues, returning the ones that were repaired and
+// the stray directories that were left in place
+func (r *Repo) Repair(removeStray bool) (repaired, kept []Issue, err error) {
+	issues, err := r.Diagnose()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errors []string
+	prunedWorktrees := false
+
+	for _, issue := range issues {
+		// A single prune covers every missing worktree
+		if issue.Kind == IssueMissingWorktree && prunedWorktrees {
+			repaired = append(repaired, issue)
+			continue
+		}
+
+		if err := r.RepairIssue(issue, removeStray); err == errStrayKept {
+			kept = append(kept, issue)
+			continue
+		} else if err != nil {
+			errors = append(errors, fmt.Sprintf("  %s: %v", issue, err))
+			continue
+		}
+
+		if issue.Kind == IssueMissingWorktree {
+			prunedWorktrees = true
+		}
+		repaired = append(repaired, issue)
+	}
+
+	// Refresh administrative links for worktrees that were moved by hand
+	if _, err := r.RunGitCommand(r.MainWorktree, "worktree", "repair"); err != nil {
+		errors = append(errors, fmt.Sprintf("  git worktree repair: %v", err))
+	}
+
+	if len(errors) > 0 {
+		return repaired, kept, fmt.Errorf("failed to repair %d problem(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+
+	return repaired, kept, nil
+}
+
+// PrintDiagnosis displays all diagnosed issues
+func (r *Repo) PrintDiagnosis(issues []Issue) {
+	if len(issues) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("%s %s\n", color.YellowString("!"), issue)
+	}
+}
@@ -0,0 +1,179 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnoseAndRepair(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	if _, err := repo.CreateNewBranch("gone", "gone"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	stray := filepath.Join(repo.WorktreesDir, "stray")
+	if err := os.MkdirAll(stray, 0755); err != nil {
+		t.Fatalf("failed to create stray dir: %v", err)
+	}
+	if err := repo.AddAlwaysCopy(".env", ""); err != nil {
+		t.Fatalf("AddAlwaysCopy failed: %v", err)
+	}
+	if err := os.RemoveAll(repo.GetWorktreePath("gone")); err != nil {
+		t.Fatalf("failed to remove worktree dir: %v", err)
+	}
+	if err := os.Remove(repo.excludePath()); err != nil {
+		t.Fatalf("failed to remove exclude file: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	issues, err := repo.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+
+	kinds := map[IssueKind]bool{}
+	for _, issue := range issues {
+		kinds[issue.Kind] = true
+	}
+	for _, kind := range []IssueKind{IssueMissingWorktree, IssueStrayDirectory, IssueMissingCopySource, IssueMissingExcludeFile} {
+		if !kinds[kind] {
+			t.Fatalf("expected issue kind %d in %v", kind, issues)
+		}
+	}
+
+	if _, _, err := repo.Repair(true); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	issues, err = repo.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues after repair, got %v", issues)
+	}
+	if _, err := os.Stat(stray); !os.IsNotExist(err) {
+		t.Fatalf("expected stray directory to be removed")
+	}
+}
+
+func TestDiagnoseBrokenSkipLink(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("README.md", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	// Point the symlink somewhere stale, as if main had moved
+	link := filepath.Join(wt.Path, "README.md")
+	if err := os.Remove(link); err != nil {
+		t.Fatalf("failed to remove link: %v", err)
+	}
+	if err := os.Symlink("/nonexistent/README.md", link); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+
+	issues, err := repo.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Kind != IssueBrokenSkipLink {
+		t.Fatalf("expected one broken skip link, got %v", issues)
+	}
+
+	if _, _, err := repo.Repair(false); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatalf("expected symlink after repair: %v", err)
+	}
+	if target != filepath.Join(repo.MainWorktree.Path, "README.md") {
+		t.Fatalf("expected link to main, got %q", target)
+	}
+}
+
+func TestRepair_KeepsStrayDirectoryWithoutError(t *testing.T) {
+	repo := setupTestRepo(t)
+	stray := filepath.Join(repo.WorktreesDir, "stray")
+	if err := os.MkdirAll(stray, 0755); err != nil {
+		t.Fatalf("failed to create stray dir: %v", err)
+	}
+	if err := os.Remove(repo.excludePath()); err != nil {
+		t.Fatalf("failed to remove exclude file: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	repaired, kept, err := repo.Repair(false)
+	if err != nil {
+		t.Fatalf("expected a stray directory not to fail repair, got %v", err)
+	}
+	if len(repaired) != 1 || repaired[0].Kind != IssueMissingExcludeFile {
+		t.Fatalf("expected the exclude file to be repaired, got %v", repaired)
+	}
+	if len(kept) != 1 || kept[0].Path != stray {
+		t.Fatalf("expected the stray directory to be kept, got %v", kept)
+	}
+	assertExists(t, stray, true)
+}
+
+func TestDiagnoseBrokenHardlinkAndMissingCopy(t *testing.T) {
+	repo := setupTestRepo(t)
+	writeTestFile(t, filepath.Join(repo.MainWorktree.Path, "app.env"), "APP=1\n")
+	runGit(t, repo.MainWorktree.Path, "add", "app.env")
+	runGit(t, repo.MainWorktree.Path, "commit", "-m", "add env")
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("README.md", SkipHardlink); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	if err := repo.SkipFile("app.env", SkipCopy); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	// Editors that save by rename break hardlinks
+	readme := filepath.Join(wt.Path, "README.md")
+	if err := os.Remove(readme); err != nil {
+		t.Fatalf("failed to remove hardlink: %v", err)
+	}
+	writeTestFile(t, readme, "detached\n")
+	env := filepath.Join(wt.Path, "app.env")
+	if err := os.Remove(env); err != nil {
+		t.Fatalf("failed to remove copy: %v", err)
+	}
+
+	issues, err := repo.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	if len(issues) != 2 || issues[0].Kind != IssueBrokenSkipLink || issues[1].Kind != IssueBrokenSkipLink {
+		t.Fatalf("expected two broken skipped files, got %v", issues)
+	}
+
+	if _, _, err := repo.Repair(false); err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	info, err := os.Stat(readme)
+	if err != nil {
+		t.Fatalf("expected README.md after repair: %v", err)
+	}
+	mainInfo, err := os.Stat(filepath.Join(repo.MainWorktree.Path, "README.md"))
+	if err != nil {
+		t.Fatalf("failed to stat main README.md: %v", err)
+	}
+	if !os.SameFile(info, mainInfo) {
+		t.Fatalf("expected README.md to be hard-linked to main again")
+	}
+	assertExists(t, env, true)
+}
@@ -8,10 +8,63 @@ import (
 	"strings"
 )
 
+// ExcludeScope identifies which exclude file a pattern lives in
+type ExcludeScope string
+
+const (
+	ExcludeShared ExcludeScope = "shared" // .git/info/exclude, applies to all worktrees
+	ExcludeLocal  ExcludeScope = "local"  // Per-worktree exclude file
+)
+
+// ExcludeEntry is an exclude pattern together with its scope
+type ExcludeEntry struct {
+	Pattern string
+	Scope   ExcludeScope
+}
+
 func (r *Repo) excludePath() string {
 	return filepath.Join(r.MainWorktree.Path, ".git", "info", "exclude")
 }
 
+// localExcludePath returns the worktree-specific exclude file, kept in the
+// worktree's own git directory
+func (r *Repo) localExcludePath(wt *Worktree) (string, error) {
+	output, err := r.RunGitCommand(wt, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to find git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(output))
+	return filepath.Join(gitDir, "info", "wrk-exclude"), nil
+}
+
+// ensureLocalExcludeFile creates the worktree-specific exclude file and points
+// the worktree's core.excludesFile at it
+func (r *Repo) ensureLocalExcludeFile(wt *Worktree) (string, error) {
+	localPath, err := r.localExcludePath(wt)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(localPath); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create info directory: %w", err)
+		}
+		if err := os.WriteFile(localPath, []byte("# wrk exclude --local patterns for this worktree\n"), 0644); err != nil {
+			return "", fmt.Errorf("failed to create local exclude file: %w", err)
+		}
+	}
+
+	// Per-worktree config needs the extension enabled in the shared config
+	if _, err := r.RunGitCommand(wt, "config", "extensions.worktreeConfig", "true"); err != nil {
+		return "", fmt.Errorf("failed to enable worktree config: %w", err)
+	}
+	if _, err := r.RunGitCommand(wt, "config", "--worktree", "core.excludesFile", localPath); err != nil {
+		return "", fmt.Errorf("failed to set core.excludesFile: %w", err)
+	}
+
+	return localPath, nil
+}
+
 // ExcludePattern adds a pattern to .git/info/exclude
 func (r *Repo) ExcludePattern(pattern string) error {
 	excludePath := r.excludePath()
@@ -21,6 +74,27 @@ func (r *Repo) ExcludePattern(pattern string) error {
 		return fmt.Errorf("exclude file does not exist: %s", excludePath)
 	}
 
+	return addPatternToFile(excludePath, pattern)
+}
+
+// LocalExcludePattern adds a pattern to the current worktree's own exclude file.
+// Note that core.excludesFile takes the place of any global excludes file in
+// this worktree.
+func (r *Repo) LocalExcludePattern(pattern string) error {
+	if r.CurrentWorktree == nil {
+		return fmt.Errorf("not in a worktree")
+	}
+
+	localPath, err := r.ensureLocalExcludeFile(r.CurrentWorktree)
+	if err != nil {
+		return err
+	}
+
+	return addPatternToFile(localPath, pattern)
+}
+
+// addPatternToFile appends a pattern to an exclude file
+func addPatternToFile(excludePath, pattern string) error {
 	// Read all lines (including comments and empty lines)
 	content, err := os.ReadFile(excludePath)
 	if err != nil {
@@ -61,11 +135,31 @@ func (r *Repo) ExcludePattern(pattern string) error {
 
 // UnexcludePattern removes a pattern from .git/info/exclude
 func (r *Repo) UnexcludePattern(pattern string) error {
-	excludePath := r.excludePath()
+	return removePatternFromFile(r.excludePath(), pattern)
+}
+
+// LocalUnexcludePattern removes a pattern from the current worktree's own exclude file
+func (r *Repo) LocalUnexcludePattern(pattern string) error {
+	if r.CurrentWorktree == nil {
+		return fmt.Errorf("not in a worktree")
+	}
+
+	localPath, err := r.localExcludePath(r.CurrentWorktree)
+	if err != nil {
+		return err
+	}
 
+	return removePatternFromFile(localPath, pattern)
+}
+
+// removePatternFromFile removes a pattern from an exclude file
+func removePatternFromFile(excludePath, pattern string) error {
 	// Read all lines (including comments and empty lines)
 	content, err := os.ReadFile(excludePath)
 	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("pattern not found in exclude list")
+		}
 		return fmt.Errorf("failed to read exclude file: %w", err)
 	}
 
@@ -103,9 +197,50 @@ func (r *Repo) UnexcludePattern(pattern string) error {
 	return nil
 }
 
-// ListExcludedPatterns returns all exclude patterns
+// ListExcludedPatterns returns all shared exclude patterns
 func (r *Repo) ListExcludedPatterns() ([]string, error) {
-	f, err := os.Open(r.excludePath())
+	return readPatternsFromFile(r.excludePath())
+}
+
+// ListLocalExcludedPatterns returns the exclude patterns specific to a worktree
+func (r *Repo) ListLocalExcludedPatterns(wt *Worktree) ([]string, error) {
+	localPath, err := r.localExcludePath(wt)
+	if err != nil {
+		return nil, err
+	}
+	return readPatternsFromFile(localPath)
+}
+
+// ListExcludeEntries returns the shared patterns followed by the current
+// worktree's local patterns
+func (r *Repo) ListExcludeEntries() ([]ExcludeEntry, error) {
+	shared, err := r.ListExcludedPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ExcludeEntry
+	for _, pattern := range shared {
+		entries = append(entries, ExcludeEntry{Pattern: pattern, Scope: ExcludeShared})
+	}
+
+	if r.CurrentWorktree != nil {
+		local, err := r.ListLocalExcludedPatterns(r.CurrentWorktree)
+		if err != nil {
+			return nil, err
+		}
+		for _, pattern := range local {
+			entries = append(entries, ExcludeEntry{Pattern: pattern, Scope: ExcludeLocal})
+		}
+	}
+
+	return entries, nil
+}
+
+// readPatternsFromFile returns the patterns in an exclude file, ignoring
+// comments and blank lines
+func readPatternsFromFile(excludePath string) ([]string, error) {
+	f, err := os.Open(excludePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []string{}, nil
@@ -131,20 +266,24 @@ func (r *Repo) ListExcludedPatterns() ([]string, error) {
 	return patterns, nil
 }
 
-// PrintExcludedPatterns displays all excluded patterns
+// PrintExcludedPatterns displays all shared and local excluded patterns
 func (r *Repo) PrintExcludedPatterns() error {
-	patterns, err := r.ListExcludedPatterns()
+	entries, err := r.ListExcludeEntries()
 	if err != nil {
 		return err
 	}
 
-	if len(patterns) == 0 {
+	if len(entries) == 0 {
 		fmt.Println("No excluded patterns")
 		return nil
 	}
 
-	for _, pattern := range patterns {
-		fmt.Printf("%s\n", pattern)
+	for _, entry := range entries {
+		if entry.Scope == ExcludeLocal {
+			fmt.Printf("%s (local)\n", entry.Pattern)
+		} else {
+			fmt.Printf("%s\n", entry.Pattern)
+		}
 	}
 
 	return nil
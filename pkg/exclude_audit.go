@@ -0,0 +1,271 @@
+package pkg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// IgnoreMatch explains why git ignores (or doesn't ignore) a path
+type IgnoreMatch struct {
+	Path    string // Path as given, relative to the worktree
+	Source  string // File containing the matching pattern, empty if not ignored
+	Kind    string // .gitignore, info/exclude, local exclude or global excludes
+	Line    int    // Line number of the pattern in Source
+	Pattern string // Matching pattern
+}
+
+// Ignored reports whether the path is ignored
+func (m IgnoreMatch) Ignored() bool {
+	return m.Source != "" && !strings.HasPrefix(m.Pattern, "!")
+}
+
+// ExplainIgnore reports which ignore source matches each path in a worktree.
+// With no paths, it explains every ignored untracked path in the worktree.
+func (r *Repo) ExplainIgnore(wt *Worktree, paths ...string) ([]IgnoreMatch, error) {
+	if len(paths) == 0 {
+		ignored, err := r.listIgnoredFiles(wt)
+		if err != nil {
+			return nil, err
+		}
+		if len(ignored) == 0 {
+			return nil, nil
+		}
+		paths = ignored
+	}
+
+	args := append([]string{"-C", wt.Path, "check-ignore", "-v", "--non-matching", "--"}, paths...)
+	if GlobalFlags.Verbose {
+		fmt.Fprintf(os.Stderr, "Running: git %s\n", strings.Join(args, " "))
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+
+	// check-ignore exits 1 when nothing is ignored, which still produces
+	// output; anything else is a fatal error whose message isn't a match
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("failed to check ignore rules: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	localPath, _ := r.localExcludePath(wt)
+
+	var matches []IgnoreMatch
+	for _, line := range strings.Split(string(output), "\n") {
+		// Format: <source>:<line>:<pattern><TAB><path>
+		info, path, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		match := IgnoreMatch{Path: path}
+		source, rest, _ := strings.Cut(info, ":")
+		lineNum, pattern, _ := strings.Cut(rest, ":")
+		if source != "" {
+			match.Source = source
+			match.Line, _ = strconv.Atoi(lineNum)
+			match.Pattern = pattern
+			match.Kind = r.ignoreSourceKind(wt, source, localPath)
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// ignoreSourceKind classifies an ignore source file reported by check-ignore
+func (r *Repo) ignoreSourceKind(wt *Worktree, source, localPath string) string {
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(wt.Path, source)
+	}
+	source = filepath.Clean(source)
+
+	switch {
+	case source == filepath.Clean(r.excludePath()):
+		return "info/exclude"
+	case localPath != "" && source == filepath.Clean(localPath):
+		return "local exclude"
+	case filepath.Base(source) == ".gitignore":
+		return ".gitignore"
+	default:
+		return "global excludes"
+	}
+}
+
+// listIgnoredFiles lists ignored untracked paths in a worktree, collapsing
+// fully ignored directories
+func (r *Repo) listIgnoredFiles(wt *Worktree) ([]string, error) {
+	output, err := r.RunGitCommand(wt, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ignored files: %w", err)
+	}
+	return splitLines(output), nil
+}
+
+// PrintExplainIgnore displays which ignore source matches each path
+func (r *Repo) PrintExplainIgnore(wt *Worktree, paths ...string) error {
+	matches, err := r.ExplainIgnore(wt, paths...)
+	if err != nil {
+		return err
+	}
+
+	if len(matches) == 0 {
+		fmt.Println("No ignored files")
+		return nil
+	}
+
+	for _, match := range matches {
+		switch {
+		case match.Source == "":
+			fmt.Printf("%s: not ignored\n", match.Path)
+		case !match.Ignored():
+			fmt.Printf("%s: not ignored, re-included by '%s' (%s:%d, %s)\n", match.Path, match.Pattern, match.Source, match.Line, match.Kind)
+		default:
+			fmt.Printf("%s: ignored by '%s' (%s:%d, %s)\n", match.Path, match.Pattern, match.Source, match.Line, match.Kind)
+		}
+	}
+
+	return nil
+}
+
+// ExcludeAudit lists the untracked paths an exclude pattern matches in each worktree
+type ExcludeAudit struct {
+	Entry   ExcludeEntry
+	Owner   *Worktree           // Worktree a local pattern belongs to, nil for shared
+	Matches map[string][]string // Worktree name to matched paths
+}
+
+// Unused reports whether the pattern matches nothing in any worktree
+func (a ExcludeAudit) Unused() bool {
+	for _, paths := range a.Matches {
+		if len(paths) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditExcludes reports the paths each shared pattern matches across all
+// worktrees, and each local pattern matches in its own worktree
+func (r *Repo) AuditExcludes() ([]ExcludeAudit, error) {
+	shared, err := r.ListExcludedPatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	var audits []ExcludeAudit
+	for _, pattern := range shared {
+		audit := ExcludeAudit{
+			Entry:   ExcludeEntry{Pattern: pattern, Scope: ExcludeShared},
+			Matches: make(map[string][]string),
+		}
+		for i := range r.Worktrees {
+			wt := &r.Worktrees[i]
+			matched, err := r.matchExcludePattern(wt, pattern)
+			if err != nil {
+				return nil, err
+			}
+			audit.Matches[wt.Name] = matched
+		}
+		audits = append(audits, audit)
+	}
+
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		local, err := r.ListLocalExcludedPatterns(wt)
+		if err != nil {
+			return nil, err
+		}
+		for _, pattern := range local {
+			matched, err := r.matchExcludePattern(wt, pattern)
+			if err != nil {
+				return nil, err
+			}
+			audits = append(audits, ExcludeAudit{
+				Entry:   ExcludeEntry{Pattern: pattern, Scope: ExcludeLocal},
+				Owner:   wt,
+				Matches: map[string][]string{wt.Name: matched},
+			})
+		}
+	}
+
+	return audits, nil
+}
+
+// matchExcludePattern lists untracked paths in a worktree matched by a single pattern
+func (r *Repo) matchExcludePattern(wt *Worktree, pattern string) ([]string, error) {
+	// A negation on its own can't match anything
+	if strings.HasPrefix(pattern, "!") {
+		return nil, nil
+	}
+
+	output, err := r.RunGitCommand(wt, "ls-files", "--others", "--ignored", "--directory", "--exclude", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match pattern '%s' in %s: %w", pattern, wt.Name, err)
+	}
+	return splitLines(output), nil
+}
+
+// PrintExcludeAudit displays each exclude pattern with what it matches
+func (r *Repo) PrintExcludeAudit() error {
+	audits, err := r.AuditExcludes()
+	if err != nil {
+		return err
+	}
+
+	if len(audits) == 0 {
+		fmt.Println("No excluded patterns")
+		return nil
+	}
+
+	for _, audit := range audits {
+		label := audit.Entry.Pattern
+		if audit.Owner != nil {
+			label = fmt.Sprintf("%s (local to %s)", label, audit.Owner.Name)
+		}
+
+		if audit.Unused() {
+			if strings.HasPrefix(audit.Entry.Pattern, "!") {
+				fmt.Printf("%s %s\n", label, color.YellowString("(negation, not audited)"))
+			} else {
+				fmt.Printf("%s %s\n", label, color.YellowString("(matches nothing)"))
+			}
+			continue
+		}
+
+		fmt.Println(label)
+		names := make([]string, 0, len(audit.Matches))
+		for name := range audit.Matches {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, path := range audit.Matches[name] {
+				fmt.Printf("  %s: %s\n", name, path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(output []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalExcludePattern_OnlyAffectsCurrentWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	repo.CurrentWorktree = repo.FindWorktreeByName("feature")
+
+	if err := repo.LocalExcludePattern("scratch.txt"); err != nil {
+		t.Fatalf("LocalExcludePattern failed: %v", err)
+	}
+	if err := repo.ExcludePattern("shared.txt"); err != nil {
+		t.Fatalf("ExcludePattern failed: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(wt.Path, "scratch.txt"), "x\n")
+	writeTestFile(t, filepath.Join(repo.MainWorktree.Path, "scratch.txt"), "x\n")
+
+	if !isIgnored(t, wt.Path, "scratch.txt") {
+		t.Fatalf("expected scratch.txt to be ignored in feature worktree")
+	}
+	if isIgnored(t, repo.MainWorktree.Path, "scratch.txt") {
+		t.Fatalf("expected scratch.txt not to be ignored in main worktree")
+	}
+
+	entries, err := repo.ListExcludeEntries()
+	if err != nil {
+		t.Fatalf("ListExcludeEntries failed: %v", err)
+	}
+	scopes := map[string]ExcludeScope{}
+	for _, entry := range entries {
+		scopes[entry.Pattern] = entry.Scope
+	}
+	if scopes["scratch.txt"] != ExcludeLocal || scopes["shared.txt"] != ExcludeShared {
+		t.Fatalf("unexpected scopes: %v", entries)
+	}
+
+	if err := repo.LocalUnexcludePattern("scratch.txt"); err != nil {
+		t.Fatalf("LocalUnexcludePattern failed: %v", err)
+	}
+	if isIgnored(t, wt.Path, "scratch.txt") {
+		t.Fatalf("expected scratch.txt to no longer be ignored")
+	}
+}
+
+func isIgnored(t *testing.T, dir, path string) bool {
+	t.Helper()
+	cmd := exec.Command("git", "check-ignore", "-q", path)
+	cmd.Dir = dir
+	return cmd.Run() == nil
+}
+
+func TestExplainIgnoreAndAudit(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, ".gitignore"), "*.tmp\n")
+	runGit(t, main, "add", ".gitignore")
+	runGit(t, main, "commit", "-m", "ignore")
+
+	if err := repo.ExcludePattern("*.log"); err != nil {
+		t.Fatalf("ExcludePattern failed: %v", err)
+	}
+	if err := repo.ExcludePattern("nothing-here/"); err != nil {
+		t.Fatalf("ExcludePattern failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "debug.log"), "x\n")
+	writeTestFile(t, filepath.Join(main, "cache.tmp"), "x\n")
+
+	matches, err := repo.ExplainIgnore(repo.MainWorktree, "debug.log", "cache.tmp", "README.md")
+	if err != nil {
+		t.Fatalf("ExplainIgnore failed: %v", err)
+	}
+	kinds := map[string]string{}
+	for _, match := range matches {
+		kinds[match.Path] = match.Kind
+	}
+	if kinds["debug.log"] != "info/exclude" || kinds["cache.tmp"] != ".gitignore" || kinds["README.md"] != "" {
+		t.Fatalf("unexpected ignore sources: %+v", matches)
+	}
+
+	// Only README.md isn't ignored, so check-ignore exits 1 and that's fine
+	if matches, err := repo.ExplainIgnore(repo.MainWorktree, "README.md"); err != nil || len(matches) != 1 || matches[0].Ignored() {
+		t.Fatalf("expected README.md not to be ignored, got %+v %v", matches, err)
+	}
+	// A path outside the repository is a fatal error, not a match
+	if matches, err := repo.ExplainIgnore(repo.MainWorktree, "/outside/repo.log"); err == nil {
+		t.Fatalf("expected ExplainIgnore to fail for a path outside the repository, got %+v", matches)
+	}
+
+	audits, err := repo.AuditExcludes()
+	if err != nil {
+		t.Fatalf("AuditExcludes failed: %v", err)
+	}
+	for _, audit := range audits {
+		switch audit.Entry.Pattern {
+		case "*.log":
+			if audit.Unused() {
+				t.Fatalf("expected *.log to match debug.log, got %v", audit.Matches)
+			}
+		case "nothing-here/":
+			if !audit.Unused() {
+				t.Fatalf("expected nothing-here/ to be unused, got %v", audit.Matches)
+			}
+		}
+	}
+}
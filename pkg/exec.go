@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ExitCodeError carries the exit code of a command run on the user's behalf,
+// so wrk can exit with the same code
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// WorktreeEnv returns the current environment with WRK_* variables describing
+// a worktree, as given to post-create commands, foreach and exec
+func (r *Repo) WorktreeEnv(wt *Worktree) []string {
+	return append(os.Environ(),
+		"WRK_REPO="+r.Name,
+		"WRK_MAIN_PATH="+r.MainWorktree.Path,
+		"WRK_WORKTREES_DIR="+r.WorktreesDir,
+		"WRK_WORKTREE="+wt.Name,
+		"WRK_WORKTREE_PATH="+wt.Path,
+		"WRK_BRANCH="+wt.Branch,
+	)
+}
+
+// Exec runs a command in a worktree with the terminal attached. A non-zero
+// exit is returned as an ExitCodeError.
+func (r *Repo) Exec(wt *Worktree, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = wt.Path
+	cmd.Env = r.WorktreeEnv(wt)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		// Killed by a signal
+		if code < 0 {
+			code = 1
+		}
+		return &ExitCodeError{Code: code}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestExec_RunsInWorktreeWithEnv(t *testing.T) {
+	repo := setupTestRepo(t)
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	wt := repo.FindWorktreeByName("feature")
+	writeTestFile(t, filepath.Join(wt.Path, "marker"), "x")
+
+	script := `test -f marker && test "$WRK_WORKTREE" = feature && test "$WRK_BRANCH" = feature && test "$WRK_WORKTREE_PATH" = "$PWD"`
+	if err := repo.Exec(wt, "sh", "-c", script); err != nil {
+		t.Fatalf("expected command to run in the worktree with WRK_* set: %v", err)
+	}
+
+	err := repo.Exec(wt, "sh", "-c", "exit 3")
+	var exitErr *ExitCodeError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+
+	if err := repo.Exec(wt, "wrk-no-such-command"); err == nil || errors.As(err, &exitErr) {
+		t.Fatalf("expected a plain error for a missing command, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// ShellCommand turns command line arguments into a bash command. A single
+// argument is taken as a shell command line, so pipes and && work when it's
+// quoted. Several arguments are quoted one by one so they reach the command
+// exactly as given.
+func ShellCommand(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes an argument for bash, leaving plain words alone
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// outputMu keeps lines from commands running in parallel from interleaving
+var outputMu sync.Mutex
+
+// runPrefixed runs a command, streaming its stdout as "> line" and stderr as
+// "! line", each after the given prefix
+func runPrefixed(cmd *exec.Cmd, prefix string) error {
+	// Create pipes for stdout and stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	// Start the command
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start: %w", err)
+	}
+
+	// Function to prefix and print lines
+	printPrefixed := func(reader io.Reader, output *os.File) {
+		scanner := bufio.NewScanner(reader)
+		for scanner.Scan() {
+			text := scanner.Text()
+			outputMu.Lock()
+			if output == os.Stderr {
+				lowerText := strings.ToLower(text)
+				if strings.Contains(lowerText, "error") {
+					fmt.Fprintf(output, "%s! %s\n", prefix, color.RedString(text))
+				} else if strings.Contains(lowerText, "warn") {
+					fmt.Fprintf(output, "%s! %s\n", prefix, color.YellowString(text))
+				} else {
+					fmt.Fprintf(output, "%s! %s\n", prefix, color.RedString(text))
+				}
+			} else {
+				fmt.Fprintf(output, "%s> %s\n", prefix, color.BlueString(text))
+			}
+			outputMu.Unlock()
+		}
+	}
+
+	// Process stdout and stderr concurrently
+	done := make(chan bool, 2)
+	go func() {
+		printPrefixed(stdout, os.Stdout)
+		done <- true
+	}()
+	go func() {
+		printPrefixed(stderr, os.Stderr)
+		done <- true
+	}()
+
+	// Wait for both goroutines to finish
+	<-done
+	<-done
+
+	// Wait for the command to complete
+	return cmd.Wait()
+}
+
+// ForeachResult is the outcome of running a command in one worktree
+type ForeachResult struct {
+	Worktree *Worktree
+	Err      error
+	Duration time.Duration
+}
+
+// Foreach runs a shell command in each worktree, up to parallel at a time,
+// streaming output prefixed with the worktree name. Results are returned in
+// the order of worktrees.
+func (r *Repo) Foreach(worktrees []*Worktree, command string, parallel int) []ForeachResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	width := 0
+	for _, wt := range worktrees {
+		width = max(width, len(wt.Name))
+	}
+
+	results := make([]ForeachResult, len(worktrees))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, wt := range worktrees {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			cmd := exec.Command("bash", "-c", command)
+			cmd.Dir = wt.Path
+			cmd.Env = r.WorktreeEnv(wt)
+			// Only a single command at a time can sensibly read the terminal
+			if parallel == 1 {
+				cmd.Stdin = os.Stdin
+			}
+
+			prefix := color.CyanString("%-*s", width, wt.Name) + " "
+			start := time.Now()
+			err := runPrefixed(cmd, prefix)
+			results[i] = ForeachResult{Worktree: wt, Err: err, Duration: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// PrintForeachSummary displays whether the command passed in each worktree,
+// returning an error if any failed
+func PrintForeachSummary(results []ForeachResult) error {
+	failed := 0
+	fmt.Println()
+	for _, result := range results {
+		duration := result.Duration.Round(100 * time.Millisecond)
+		if result.Err != nil {
+			failed++
+			fmt.Printf("%s %s (%s): %v\n", color.RedString("✗"), result.Worktree.Name, duration, result.Err)
+		} else {
+			fmt.Printf("%s %s (%s)\n", color.GreenString("✓"), result.Worktree.Name, duration)
+		}
+	}
+	fmt.Printf("%d passed, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d worktree(s)", failed, len(results))
+	}
+	return nil
+}
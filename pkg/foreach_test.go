@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestForeach_ReportsFailuresPerWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+	for _, name := range []string{"feature-a", "feature-b", "bugfix"} {
+		if _, err := repo.CreateNewBranch(name, name); err != nil {
+			t.Fatalf("CreateNewBranch failed: %v", err)
+		}
+	}
+	repo = reloadTestRepo(t)
+
+	worktrees, err := repo.SelectWorktrees("feature-*")
+	if err != nil {
+		t.Fatalf("SelectWorktrees failed: %v", err)
+	}
+	if len(worktrees) != 2 || worktrees[0].Name != "feature-a" || worktrees[1].Name != "feature-b" {
+		t.Fatalf("expected feature-a and feature-b in order, got %v", worktrees)
+	}
+	if _, err := repo.SelectWorktrees("nothing-*"); err == nil {
+		t.Fatalf("expected error when nothing matches")
+	}
+
+	writeTestFile(t, filepath.Join(worktrees[0].Path, "marker"), "x")
+	results := repo.Foreach(worktrees, "test -f marker", 2)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Worktree != worktrees[0] || results[0].Err != nil {
+		t.Fatalf("expected feature-a to pass, got %+v", results[0])
+	}
+	if results[1].Worktree != worktrees[1] || results[1].Err == nil {
+		t.Fatalf("expected feature-b to fail, got %+v", results[1])
+	}
+	if err := PrintForeachSummary(results); err == nil {
+		t.Fatalf("expected summary to report the failure")
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"make build && make test"}, "make build && make test"},
+		{[]string{"git", "fetch", "--all"}, "git fetch --all"},
+		{[]string{"grep", "a b", "src/"}, "grep 'a b' src/"},
+		{[]string{"echo", "it's", ""}, `echo 'it'\''s' ''`},
+		{[]string{"echo", "$HOME"}, "echo '$HOME'"},
+	}
+
+	for _, tt := range tests {
+		if got := ShellCommand(tt.args); got != tt.expected {
+			t.Fatalf("ShellCommand(%q) = %q, want %q", tt.args, got, tt.expected)
+		}
+	}
+}
@@ -50,6 +50,11 @@ func (r *Repo) GetWorktreeDisplay(wt *Worktree) string {
 		prefix = "  "
 	}
 
+	// Sparse worktrees only have some of the files
+	if wt.Meta.Profile != "" {
+		display += " " + color.New(color.Faint).Sprintf("(sparse: %s)", wt.Meta.Profile)
+	}
+
 	return prefix + display
 }
 
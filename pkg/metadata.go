@@ -0,0 +1,220 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorktreeMeta is what wrk records about a worktree when it creates one.
+// It is zero for worktrees wrk didn't create.
+type WorktreeMeta struct {
+	Base        string    `yaml:"base,omitempty"`        // Ref the branch was created from, or its upstream
+	Created     time.Time `yaml:"created,omitempty"`     // When the worktree was created
+	CreatedBy   string    `yaml:"createdBy,omitempty"`   // Command line that created it
+	Profile     string    `yaml:"profile,omitempty"`     // Sparse-checkout profile
+	Expires     time.Time `yaml:"expires,omitempty"`     // When a throwaway worktree should be removed
+	PromptAfter time.Time `yaml:"promptAfter,omitempty"` // When the expiry prompt may ask about it again
+}
+
+// Expired reports whether the worktree has an expiry that has passed
+func (m WorktreeMeta) Expired() bool {
+	return !m.Expires.IsZero() && time.Now().After(m.Expires)
+}
+
+// MetadataPath returns the path of the per-worktree metadata file
+func (r *Repo) MetadataPath() string {
+	return filepath.Join(r.WorktreesDir, ".metadata.yml")
+}
+
+// loadMetadata reads recorded metadata keyed by worktreeKey
+func (r *Repo) loadMetadata() (map[string]WorktreeMeta, error) {
+	metadata := make(map[string]WorktreeMeta)
+
+	data, err := os.ReadFile(r.MetadataPath())
+	if os.IsNotExist(err) {
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktree metadata: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse worktree metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// saveMetadata writes metadata, dropping entries for worktrees that no longer
+// exist. keep is the key of a worktree that isn't loaded yet because it was
+// just created.
+func (r *Repo) saveMetadata(metadata map[string]WorktreeMeta, keep string) error {
+	existing := map[string]bool{keep: true}
+	for i := range r.Worktrees {
+		existing[r.worktreeKey(&r.Worktrees[i])] = true
+	}
+	for key := range metadata {
+		if !existing[key] {
+			delete(metadata, key)
+		}
+	}
+
+	if err := r.EnsureWorktreesDir(); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal worktree metadata: %w", err)
+	}
+	if err := os.WriteFile(r.MetadataPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write worktree metadata: %w", err)
+	}
+	return nil
+}
+
+// worktreeKey returns the key a worktree's metadata and sync state are stored
+// under, its path relative to WorktreesDir. Names can't be used, since
+// feature/x and bugfix/x are both loaded as x.
+func (r *Repo) worktreeKey(wt *Worktree) string {
+	rel, err := filepath.Rel(r.WorktreesDir, wt.Path)
+	if err != nil {
+		return wt.Path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// applyMetadata attaches recorded metadata to the loaded worktrees
+func (r *Repo) applyMetadata() error {
+	metadata, err := r.loadMetadata()
+	if err != nil {
+		return err
+	}
+	for i := range r.Worktrees {
+		r.Worktrees[i].Meta = metadata[r.worktreeKey(&r.Worktrees[i])]
+	}
+	return nil
+}
+
+// SetWorktreeMeta records metadata for a worktree
+func (r *Repo) SetWorktreeMeta(wt *Worktree, meta WorktreeMeta) error {
+	metadata, err := r.loadMetadata()
+	if err != nil {
+		return err
+	}
+
+	key := r.worktreeKey(wt)
+	metadata[key] = meta
+	wt.Meta = meta
+	return r.saveMetadata(metadata, key)
+}
+
+// forgetWorktreeMeta removes a worktree's metadata
+func (r *Repo) forgetWorktreeMeta(wt *Worktree) error {
+	metadata, err := r.loadMetadata()
+	if err != nil {
+		return err
+	}
+	key := r.worktreeKey(wt)
+	if _, ok := metadata[key]; !ok {
+		return nil
+	}
+
+	delete(metadata, key)
+	return r.saveMetadata(metadata, "")
+}
+
+// newCreationMeta returns metadata for a worktree being created from base
+func newCreationMeta(base string) WorktreeMeta {
+	return WorktreeMeta{
+		Base:      base,
+		Created:   time.Now().Truncate(time.Second),
+		CreatedBy: creatorCommand(),
+	}
+}
+
+// recordCreation records a newly created worktree's metadata and adds it to
+// the global registry
+func (r *Repo) recordCreation(wt *Worktree, meta WorktreeMeta) {
+	if err := r.SetWorktreeMeta(wt, meta); err != nil {
+		// Metadata is informational, so don't fail the worktree creation
+		fmt.Fprintf(os.Stderr, "Warning: failed to record worktree metadata: %v\n", err)
+	}
+	r.registerWorktree(wt)
+}
+
+// creatorCommand returns the wrk command line being run
+func creatorCommand() string {
+	return strings.Join(append([]string{"wrk"}, os.Args[1:]...), " ")
+}
+
+// currentRef returns the branch checked out where wrk was run, or the commit if detached
+func (r *Repo) currentRef() string {
+	output, err := r.RunGitCommand(nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err == nil {
+		return strings.TrimSpace(string(output))
+	}
+	output, err = r.RunGitCommand(nil, "rev-parse", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// branchUpstream returns a local branch's upstream, empty if it has none
+func (r *Repo) branchUpstream(branch string) string {
+	output, err := r.RunGitCommand(nil, "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// formatAge describes how long ago a time was in the largest whole unit
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	if age < time.Minute {
+		return "just now"
+	}
+	return formatDuration(age) + " ago"
+}
+
+// formatDuration describes a duration in its largest whole unit
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// GetWorktreeDetails describes a worktree's base, how far it has moved from
+// it and when it was created, empty if nothing is known
+func (r *Repo) GetWorktreeDetails(wt *Worktree) string {
+	var details []string
+
+	if wt.Meta.Base != "" {
+		base := "base " + wt.Meta.Base
+		if behind, ahead, err := r.aheadBehind(wt, wt.Meta.Base); err == nil {
+			base += fmt.Sprintf(" (%d ahead, %d behind)", ahead, behind)
+		}
+		details = append(details, base)
+	}
+	if !wt.Meta.Created.IsZero() {
+		details = append(details, "created "+formatAge(wt.Meta.Created))
+	}
+	if wt.Meta.Expired() {
+		details = append(details, "expired")
+	} else if !wt.Meta.Expires.IsZero() {
+		details = append(details, "expires in "+formatDuration(time.Until(wt.Meta.Expires)))
+	}
+
+	return strings.Join(details, ", ")
+}
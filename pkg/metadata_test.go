@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorktreeMeta_RecordedAndRemoved(t *testing.T) {
+	repo := setupTestRepo(t)
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	wt := repo.FindWorktreeByName("feature")
+	if wt.Meta.Base != "main" {
+		t.Fatalf("expected base main, got %q", wt.Meta.Base)
+	}
+	if wt.Meta.Created.IsZero() || !strings.HasPrefix(wt.Meta.CreatedBy, "wrk") {
+		t.Fatalf("expected creation time and command, got %+v", wt.Meta)
+	}
+	if details := repo.GetWorktreeDetails(wt); !strings.Contains(details, "base main (0 ahead, 0 behind)") {
+		t.Fatalf("unexpected details %q", details)
+	}
+
+	if err := repo.RemoveWorktree(wt, true); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	metadata, err := repo.loadMetadata()
+	if err != nil {
+		t.Fatalf("loadMetadata failed: %v", err)
+	}
+	if _, ok := metadata["feature"]; ok {
+		t.Fatalf("expected metadata to be removed with the worktree")
+	}
+}
+
+func TestWorktreeMeta_SlashNamedWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+	if _, err := repo.CreateNewBranch("feature/auth", "feature/auth"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	wt := repo.FindWorktreeByBranch("feature/auth")
+	if wt.Meta.Base != "main" || wt.Meta.Created.IsZero() {
+		t.Fatalf("expected metadata to round-trip, got %+v", wt.Meta)
+	}
+
+	// Saving metadata for another worktree keeps this one's
+	if _, err := repo.CreateNewBranch("other", "other"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if wt := repo.FindWorktreeByBranch("feature/auth"); wt.Meta.Base != "main" {
+		t.Fatalf("expected metadata to survive a later save, got %+v", wt.Meta)
+	}
+}
+
+func TestWorktreeMeta_SameBaseNameInDifferentDirectories(t *testing.T) {
+	repo := setupTestRepo(t)
+	for _, branch := range []string{"feature/x", "bugfix/x"} {
+		if _, err := repo.CreateNewBranch(branch, branch); err != nil {
+			t.Fatalf("CreateNewBranch failed: %v", err)
+		}
+	}
+
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByBranch("feature/x")
+	meta := feature.Meta
+	meta.Expires = time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := repo.SetWorktreeMeta(feature, meta); err != nil {
+		t.Fatalf("SetWorktreeMeta failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if bugfix := repo.FindWorktreeByBranch("bugfix/x"); !bugfix.Meta.Expires.IsZero() || bugfix.Meta.Created.IsZero() {
+		t.Fatalf("expected bugfix/x to keep its own metadata, got %+v", bugfix.Meta)
+	}
+	if feature := repo.FindWorktreeByBranch("feature/x"); feature.Meta.Expires.IsZero() {
+		t.Fatalf("expected feature/x to have an expiry, got %+v", feature.Meta)
+	}
+
+	if err := repo.RemoveWorktree(repo.FindWorktreeByBranch("feature/x"), true); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if bugfix := repo.FindWorktreeByBranch("bugfix/x"); bugfix.Meta.Created.IsZero() {
+		t.Fatalf("expected bugfix/x metadata to survive removing feature/x")
+	}
+}
@@ -0,0 +1,145 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pullRefs are the refs forges publish pull request heads under, GitHub's
+// first and then GitLab's
+var pullRefs = []string{"refs/pull/%d/head", "refs/merge-requests/%d/head"}
+
+// PullBranch returns the local branch a pull request is checked out on
+func PullBranch(number int) string {
+	return fmt.Sprintf("pr/%d", number)
+}
+
+// PullNumber returns the pull request a pr/<n> branch was created for
+func PullNumber(branch string) (int, bool) {
+	after, ok := strings.CutPrefix(branch, "pr/")
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.Atoi(after)
+	return number, err == nil && number > 0
+}
+
+// fetchPull fetches a pull request head into FETCH_HEAD, returning the ref it
+// was found under
+func (r *Repo) fetchPull(number int, remote string) (string, error) {
+	for _, pattern := range pullRefs {
+		ref := fmt.Sprintf(pattern, number)
+		if _, err := r.RunGitCommand(nil, "fetch", remote, ref); err == nil {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("pull request %d not found on remote '%s'", number, remote)
+}
+
+// CheckoutPull fetches a pull request into a pr/<n> branch that tracks the
+// pull request ref, then adds a worktree for it. An existing pr/<n> branch is
+// only moved if that keeps its commits, or with force.
+func (r *Repo) CheckoutPull(number int, remote string, force bool) (*Worktree, error) {
+	branch := PullBranch(number)
+	if existing := r.FindWorktreeByBranch(branch); existing != nil {
+		return existing, fmt.Errorf("worktree already exists for pull request %d (use --refresh to update it)", number)
+	}
+
+	ref, err := r.fetchPull(number, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := ""
+	if r.LocalBranchExists(branch) {
+		output, err := r.RunGitCommand(nil, "rev-parse", "--verify", "refs/heads/"+branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read branch '%s': %w", branch, err)
+		}
+		previous = strings.TrimSpace(string(output))
+
+		if _, err := r.RunGitCommand(nil, "merge-base", "--is-ancestor", previous, "FETCH_HEAD"); err != nil && !force {
+			return nil, fmt.Errorf("branch '%s' has commits that aren't in pull request %d (use --force to reset it)", branch, number)
+		}
+	}
+
+	// The branch isn't checked out anywhere, so it can be moved to the new head
+	if _, err := r.RunGitCommand(nil, "branch", "--force", branch, "FETCH_HEAD"); err != nil {
+		return nil, fmt.Errorf("failed to create branch '%s': %w", branch, err)
+	}
+
+	wt, err := r.trackPull(branch, remote, ref)
+	if err != nil {
+		r.undoPullBranch(branch, previous)
+		return nil, err
+	}
+	return wt, nil
+}
+
+// trackPull sets a pull request branch to track its ref, so git pull and
+// wrk pr --refresh know where it came from, then adds its worktree
+func (r *Repo) trackPull(branch, remote, ref string) (*Worktree, error) {
+	for key, value := range map[string]string{"remote": remote, "merge": ref} {
+		if _, err := r.RunGitCommand(nil, "config", fmt.Sprintf("branch.%s.%s", branch, key), value); err != nil {
+			return nil, fmt.Errorf("failed to set upstream of '%s': %w", branch, err)
+		}
+	}
+	return r.AddExistingBranch(branch, branch, remote)
+}
+
+// undoPullBranch puts a pull request branch back to the commit it was at
+// before CheckoutPull, or deletes it along with its config if it was new
+func (r *Repo) undoPullBranch(branch, previous string) {
+	if previous != "" {
+		_, _ = r.RunGitCommand(nil, "branch", "--force", branch, previous)
+		return
+	}
+	_, _ = r.RunGitCommand(nil, "branch", "-D", branch)
+	_, _ = r.RunGitCommand(nil, "config", "--remove-section", "branch."+branch)
+}
+
+// RefreshPull fetches new commits for a pull request worktree. The branch is
+// fast-forwarded, or with force reset to the pull request head, keeping
+// uncommitted changes where they don't conflict.
+func (r *Repo) RefreshPull(wt *Worktree, force bool) (string, error) {
+	if _, ok := PullNumber(wt.Branch); !ok {
+		return "", fmt.Errorf("worktree '%s' is not a pull request checkout", wt.Name)
+	}
+
+	remote, err := r.RunGitCommand(nil, "config", fmt.Sprintf("branch.%s.remote", wt.Branch))
+	if err != nil {
+		return "", fmt.Errorf("branch '%s' has no pull request remote", wt.Branch)
+	}
+	ref, err := r.RunGitCommand(nil, "config", fmt.Sprintf("branch.%s.merge", wt.Branch))
+	if err != nil {
+		return "", fmt.Errorf("branch '%s' has no pull request ref", wt.Branch)
+	}
+
+	if _, err := r.RunGitCommand(wt, "fetch", strings.TrimSpace(string(remote)), strings.TrimSpace(string(ref))); err != nil {
+		return "", fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+
+	behind, ahead, err := r.aheadBehind(wt, "FETCH_HEAD")
+	if err != nil {
+		return "", err
+	}
+	if behind == 0 {
+		return "already up to date", nil
+	}
+
+	if ahead == 0 {
+		if _, err := r.RunGitCommand(wt, "merge", "--ff-only", "FETCH_HEAD"); err != nil {
+			return "", fmt.Errorf("failed to fast-forward: %w", err)
+		}
+		return fmt.Sprintf("%d new commit(s)", behind), nil
+	}
+
+	if !force {
+		return "", fmt.Errorf("'%s' has diverged from the pull request by %d commit(s), it was force-pushed or has local commits (use --force to reset to it)", wt.Branch, ahead)
+	}
+	if _, err := r.RunGitCommand(wt, "reset", "--keep", "FETCH_HEAD"); err != nil {
+		return "", fmt.Errorf("failed to reset to the pull request: %w", err)
+	}
+	return fmt.Sprintf("reset to the pull request, dropping %d commit(s)", ahead), nil
+}
@@ -0,0 +1,122 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckoutAndRefreshPull(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+	runGit(t, main, "push", "-u", "origin", "main")
+
+	// A contributor's clone publishes pull requests the way forges do
+	otherDir := filepath.Join(t.TempDir(), "other")
+	runGit(t, main, "clone", "-b", "main", remoteDir, otherDir)
+	runGit(t, otherDir, "config", "user.email", "tests@example.com")
+	runGit(t, otherDir, "config", "user.name", "Tests")
+	writeTestFile(t, filepath.Join(otherDir, "feature.txt"), "one\n")
+	runGit(t, otherDir, "add", "feature.txt")
+	runGit(t, otherDir, "commit", "-m", "feature")
+	runGit(t, otherDir, "push", "origin", "HEAD:refs/pull/7/head")
+	runGit(t, otherDir, "push", "origin", "HEAD:refs/merge-requests/8/head")
+
+	wt, err := repo.CheckoutPull(7, "origin", false)
+	if err != nil {
+		t.Fatalf("CheckoutPull failed: %v", err)
+	}
+	if wt.Branch != "pr/7" || wt.Path != filepath.Join(repo.WorktreesDir, "pr", "7") {
+		t.Fatalf("unexpected worktree %+v", wt)
+	}
+	if got := strings.TrimSpace(gitOutput(t, main, "config", "branch.pr/7.merge")); got != "refs/pull/7/head" {
+		t.Fatalf("expected pr/7 to track refs/pull/7/head, got %q", got)
+	}
+
+	if _, err := repo.CheckoutPull(8, "origin", false); err != nil {
+		t.Fatalf("CheckoutPull of a merge request failed: %v", err)
+	}
+	if _, err := repo.CheckoutPull(9, "origin", false); err == nil {
+		t.Fatalf("expected CheckoutPull to fail for a missing pull request")
+	}
+
+	repo = reloadTestRepo(t)
+	wt = repo.FindWorktreeByBranch("pr/7")
+	if result, err := repo.RefreshPull(wt, false); err != nil || result != "already up to date" {
+		t.Fatalf("expected up to date, got %q, %v", result, err)
+	}
+
+	// New push
+	writeTestFile(t, filepath.Join(otherDir, "feature.txt"), "two\n")
+	runGit(t, otherDir, "commit", "-am", "more")
+	runGit(t, otherDir, "push", "origin", "HEAD:refs/pull/7/head")
+	if _, err := repo.RefreshPull(wt, false); err != nil {
+		t.Fatalf("RefreshPull failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(wt.Path, "feature.txt")); err != nil || string(data) != "two\n" {
+		t.Fatalf("expected the new push to be checked out, got %q, %v", data, err)
+	}
+
+	// Force push
+	runGit(t, otherDir, "commit", "--amend", "-m", "rewritten")
+	runGit(t, otherDir, "push", "--force", "origin", "HEAD:refs/pull/7/head")
+	if _, err := repo.RefreshPull(wt, false); err == nil {
+		t.Fatalf("expected RefreshPull to refuse a force-pushed pull request")
+	}
+	if _, err := repo.RefreshPull(wt, true); err != nil {
+		t.Fatalf("forced RefreshPull failed: %v", err)
+	}
+	want := strings.TrimSpace(gitOutput(t, otherDir, "rev-parse", "HEAD"))
+	if got := strings.TrimSpace(gitOutput(t, wt.Path, "rev-parse", "HEAD")); got != want {
+		t.Fatalf("expected HEAD %s, got %s", want, got)
+	}
+}
+
+func TestCheckoutPull_KeepsLocalCommitsAndCleansUp(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+	runGit(t, main, "push", "origin", "main:refs/pull/10/head", "main:refs/pull/11/head")
+
+	// A leftover pr/10 branch with a local commit the pull request doesn't have
+	runGit(t, main, "checkout", "-q", "-b", "pr/10")
+	writeTestFile(t, filepath.Join(main, "local.txt"), "local\n")
+	runGit(t, main, "add", "local.txt")
+	runGit(t, main, "commit", "-m", "local work")
+	localHead := strings.TrimSpace(gitOutput(t, main, "rev-parse", "HEAD"))
+	runGit(t, main, "checkout", "-q", "main")
+
+	repo = reloadTestRepo(t)
+	if _, err := repo.CheckoutPull(10, "origin", false); err == nil {
+		t.Fatalf("expected CheckoutPull to refuse to drop local commits")
+	}
+	if got := strings.TrimSpace(gitOutput(t, main, "rev-parse", "pr/10")); got != localHead {
+		t.Fatalf("expected pr/10 to be left at %s, got %s", localHead, got)
+	}
+	if _, err := repo.CheckoutPull(10, "origin", true); err != nil {
+		t.Fatalf("forced CheckoutPull failed: %v", err)
+	}
+
+	// If the worktree can't be added, the new branch and its config go too
+	if err := os.MkdirAll(filepath.Join(repo.WorktreesDir, "pr", "11"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	writeTestFile(t, filepath.Join(repo.WorktreesDir, "pr", "11", "in-the-way"), "x\n")
+	if _, err := repo.CheckoutPull(11, "origin", false); err == nil {
+		t.Fatalf("expected CheckoutPull to fail when the worktree path is taken")
+	}
+	if repo.LocalBranchExists("pr/11") {
+		t.Fatalf("expected pr/11 to be deleted after the failure")
+	}
+	if output, _ := repo.RunGitCommand(nil, "config", "branch.pr/11.merge"); len(output) != 0 {
+		t.Fatalf("expected pr/11 config to be removed, got %q", output)
+	}
+}
@@ -0,0 +1,300 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegisteredWorktree is a worktree recorded in the global registry
+type RegisteredWorktree struct {
+	Name   string `yaml:"name"`
+	Branch string `yaml:"branch,omitempty"`
+	Path   string `yaml:"path"`
+}
+
+// RegisteredRepo is a repository recorded in the global registry
+type RegisteredRepo struct {
+	Name      string               `yaml:"name"`
+	Worktrees []RegisteredWorktree `yaml:"worktrees"`
+}
+
+// Registry records every repository and worktree wrk has seen on this
+// machine, keyed by main worktree path
+type Registry struct {
+	Repos map[string]RegisteredRepo `yaml:"repos"`
+}
+
+// RegistryMatch is a registered worktree found by a pattern
+type RegistryMatch struct {
+	Repo     string // Repository name
+	Worktree RegisteredWorktree
+}
+
+// Alias returns the repo/name form that identifies a match across repositories
+func (m RegistryMatch) Alias() string {
+	return m.Repo + "/" + m.Worktree.Name
+}
+
+// RegistryPath returns the registry file: $WRK_REGISTRY, or wrk/registry.yml
+// in $XDG_DATA_HOME (default ~/.local/share)
+func RegistryPath() (string, error) {
+	if path := os.Getenv("WRK_REGISTRY"); path != "" {
+		return path, nil
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "wrk", "registry.yml"), nil
+}
+
+// LoadRegistry reads the registry, dropping repositories and worktrees whose
+// directories no longer exist
+func LoadRegistry() (*Registry, error) {
+	path, err := RegistryPath()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return loadRegistry(path)
+}
+
+// lockRegistry serialises registry updates between concurrent wrk runs
+func lockRegistry(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	return lockFile(path + ".lock")
+}
+
+// loadRegistry reads and prunes the registry. The caller holds the lock.
+func loadRegistry(path string) (*Registry, error) {
+	registry := &Registry{Repos: make(map[string]RegisteredRepo)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+	if err := yaml.Unmarshal(data, registry); err != nil {
+		return nil, fmt.Errorf("failed to parse registry: %w", err)
+	}
+	if registry.Repos == nil {
+		registry.Repos = make(map[string]RegisteredRepo)
+	}
+
+	pruned := registry.prune()
+	if pruned > 0 {
+		if err := registry.save(path); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// prune drops entries whose paths have disappeared, returning how many went
+func (g *Registry) prune() int {
+	pruned := 0
+	for mainPath, repo := range g.Repos {
+		if _, err := os.Stat(mainPath); err != nil {
+			delete(g.Repos, mainPath)
+			pruned++
+			continue
+		}
+
+		var kept []RegisteredWorktree
+		for _, wt := range repo.Worktrees {
+			if _, err := os.Stat(wt.Path); err == nil {
+				kept = append(kept, wt)
+			} else {
+				pruned++
+			}
+		}
+		repo.Worktrees = kept
+		g.Repos[mainPath] = repo
+	}
+	return pruned
+}
+
+// save writes the registry, replacing the file so readers never see it half
+// written. The caller holds the lock.
+func (g *Registry) save(path string) error {
+	data, err := yaml.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write registry: %w", err)
+	}
+	return nil
+}
+
+// updateRegistry applies a change to this repository's registry entry,
+// saving only if it changed. The registry only helps navigation, so failures
+// are reported with --verbose rather than failing the command.
+func (r *Repo) updateRegistry(change func(repo *RegisteredRepo)) {
+	if r.MainWorktree == nil {
+		return
+	}
+
+	err := func() error {
+		path, err := RegistryPath()
+		if err != nil {
+			return err
+		}
+		unlock, err := lockRegistry(path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		registry, err := loadRegistry(path)
+		if err != nil {
+			return err
+		}
+
+		entry := registry.Repos[r.MainWorktree.Path]
+		updated := RegisteredRepo{Name: r.Name, Worktrees: append([]RegisteredWorktree(nil), entry.Worktrees...)}
+		change(&updated)
+		if reflect.DeepEqual(entry, updated) {
+			return nil
+		}
+
+		registry.Repos[r.MainWorktree.Path] = updated
+		return registry.save(path)
+	}()
+
+	if err != nil && GlobalFlags.Verbose {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update worktree registry: %v\n", err)
+	}
+}
+
+// registerRepo records the repository's current worktrees
+func (r *Repo) registerRepo() {
+	r.updateRegistry(func(repo *RegisteredRepo) {
+		repo.Worktrees = nil
+		for i := range r.Worktrees {
+			repo.Worktrees = append(repo.Worktrees, registeredWorktree(&r.Worktrees[i]))
+		}
+	})
+}
+
+// registerWorktree records a worktree that was created or moved
+func (r *Repo) registerWorktree(wt *Worktree) {
+	r.updateRegistry(func(repo *RegisteredRepo) {
+		repo.Worktrees = removeRegistered(repo.Worktrees, wt)
+		repo.Worktrees = append(repo.Worktrees, registeredWorktree(wt))
+	})
+}
+
+// unregisterWorktree forgets a removed worktree
+func (r *Repo) unregisterWorktree(wt *Worktree) {
+	r.updateRegistry(func(repo *RegisteredRepo) {
+		repo.Worktrees = removeRegistered(repo.Worktrees, wt)
+	})
+}
+
+// registeredWorktree converts a worktree to its registry entry, named as
+// loadWorktrees names it
+func registeredWorktree(wt *Worktree) RegisteredWorktree {
+	return RegisteredWorktree{Name: filepath.Base(wt.Path), Branch: wt.Branch, Path: wt.Path}
+}
+
+// removeRegistered drops the entry for a worktree's path
+func removeRegistered(worktrees []RegisteredWorktree, wt *Worktree) []RegisteredWorktree {
+	var kept []RegisteredWorktree
+	for _, registered := range worktrees {
+		if registered.Path != wt.Path {
+			kept = append(kept, registered)
+		}
+	}
+	return kept
+}
+
+// Matches returns every registered worktree sorted by repository, then name
+func (g *Registry) Matches() []RegistryMatch {
+	var matches []RegistryMatch
+	for _, repo := range g.Repos {
+		for _, wt := range repo.Worktrees {
+			matches = append(matches, RegistryMatch{Repo: repo.Name, Worktree: wt})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Repo != matches[j].Repo {
+			return matches[i].Repo < matches[j].Repo
+		}
+		return matches[i].Worktree.Name < matches[j].Worktree.Name
+	})
+	return matches
+}
+
+// Aliases returns the names a registered worktree can be found by: its
+// name, its branch, and both prefixed with the repository name
+func (m RegistryMatch) Aliases() []string {
+	aliases := []string{m.Worktree.Name, m.Alias()}
+	if m.Worktree.Branch != "" && m.Worktree.Branch != m.Worktree.Name {
+		aliases = append(aliases, m.Worktree.Branch, m.Repo+"/"+m.Worktree.Branch)
+	}
+	return aliases
+}
+
+// Find finds the unique registered worktree matching a pattern against its
+// aliases. Returns error if no matches or multiple matches found
+func (g *Registry) Find(pattern string) (*RegistryMatch, error) {
+	var matches []RegistryMatch
+	var names []string
+	for _, match := range g.Matches() {
+		for _, alias := range match.Aliases() {
+			if matched, _ := filepath.Match(pattern, alias); matched {
+				matches = append(matches, match)
+				names = append(names, match.Alias())
+				break
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no worktree found matching '%s' in any repository", pattern)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("pattern '%s' matches multiple worktrees:\n  %s", pattern, strings.Join(names, "\n  "))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pkg
+
+// lockFile is a no-op where flock isn't available, so concurrent runs may
+// lose registry updates
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestMain keeps every test's LoadRepo from writing the user's registry
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "wrk-registry")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("WRK_REGISTRY", filepath.Join(dir, "registry.yml"))
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestRegistry_TracksCreateRemoveAndPrunes(t *testing.T) {
+	t.Setenv("WRK_REGISTRY", filepath.Join(t.TempDir(), "registry.yml"))
+	repo := setupTestRepo(t)
+
+	feature, err := repo.CreateNewBranch("feature/login", "login")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	gone, err := repo.CreateNewBranch("gone", "gone")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	registry, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	for _, pattern := range []string{"login", "feature/*", "repo/login", "repo/feature/login"} {
+		match, err := registry.Find(pattern)
+		if err != nil {
+			t.Fatalf("Find(%q) failed: %v", pattern, err)
+		}
+		if match.Worktree.Path != feature.Path {
+			t.Fatalf("Find(%q) found %s", pattern, match.Worktree.Path)
+		}
+	}
+	if _, err := registry.Find("repo"); err != nil {
+		t.Fatalf("expected the main worktree to be registered on load: %v", err)
+	}
+
+	if err := repo.RemoveWorktree(feature, false); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	// Deleted behind wrk's back
+	if err := os.RemoveAll(gone.Path); err != nil {
+		t.Fatalf("failed to remove directory: %v", err)
+	}
+
+	registry, err = LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	for _, pattern := range []string{"login", "gone"} {
+		if _, err := registry.Find(pattern); err == nil {
+			t.Fatalf("expected %s to be gone from the registry", pattern)
+		}
+	}
+}
+
+func TestRegistry_ConcurrentUpdatesAreKept(t *testing.T) {
+	t.Setenv("WRK_REGISTRY", filepath.Join(t.TempDir(), "registry.yml"))
+	repo := setupTestRepo(t)
+
+	// Each update reads, changes and writes the whole registry, as separate
+	// wrk runs do
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := range 20 {
+		path := filepath.Join(dir, fmt.Sprintf("wt-%d", i))
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo.registerWorktree(&Worktree{Path: path})
+		}()
+	}
+	wg.Wait()
+
+	registry, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+	if got := len(registry.Repos[repo.MainWorktree.Path].Worktrees); got != 21 {
+		t.Fatalf("expected the main worktree and 20 others, got %d", got)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(os.Getenv("WRK_REGISTRY")), "*.tmp")); len(leftovers) != 0 {
+		t.Fatalf("expected no temporary files left, got %v", leftovers)
+	}
+}
+
+func TestRepoCompletion_DoesNotRegister(t *testing.T) {
+	setupTestRepo(t)
+	path := filepath.Join(t.TempDir(), "registry.yml")
+	t.Setenv("WRK_REGISTRY", path)
+
+	complete := RepoCompletion(func(repo *Repo, cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	complete(nil, nil, "")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected completion not to write the registry")
+	}
+}
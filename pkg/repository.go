@@ -18,8 +18,20 @@ type Repo struct {
 	Config          *Config    // Configuration settings
 }
 
-// LoadRepo discovers the git repository and all its worktrees
+// LoadRepo discovers the git repository and all its worktrees, recording
+// them in the global registry
 func LoadRepo() (*Repo, error) {
+	repo, err := loadRepo()
+	if err != nil {
+		return nil, err
+	}
+	repo.registerRepo()
+	return repo, nil
+}
+
+// loadRepo discovers the git repository and all its worktrees without
+// touching the registry, for shell completion, which should stay read-only
+func loadRepo() (*Repo, error) {
 	// Find the main git directory (the one with .git directory, not file)
 	mainGitDir, err := findMainGitDir()
 	if err != nil {
@@ -73,6 +85,10 @@ func LoadRepo() (*Repo, error) {
 	}
 	repo.Config = config
 
+	if err := repo.applyMetadata(); err != nil {
+		return nil, err
+	}
+
 	return repo, nil
 }
 
@@ -135,9 +151,16 @@ func (r *Repo) loadWorktrees() error {
 	return nil
 }
 
+// AllBranches lists local branches and the branches of a remote with the
+// remote prefix stripped. An empty remote lists every remote's branches, both
+// stripped and in remote/branch form.
 func (r *Repo) AllBranches(remote string) ([]string, error) {
-	// List local branches and branches from the selected remote.
-	output, err := r.RunGitCommand(nil, "for-each-ref", "--format=%(refname:short)", "refs/heads", fmt.Sprintf("refs/remotes/%s", remote))
+	remoteRefs := "refs/remotes"
+	if remote != "" {
+		remoteRefs = fmt.Sprintf("refs/remotes/%s", remote)
+	}
+
+	output, err := r.RunGitCommand(nil, "for-each-ref", "--format=%(refname)", "refs/heads", remoteRefs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w", err)
 	}
@@ -145,26 +168,97 @@ func (r *Repo) AllBranches(remote string) ([]string, error) {
 	lines := strings.Split(string(output), "\n")
 	branches := make([]string, 0, len(lines))
 	seen := make(map[string]bool, len(lines))
-	remotePrefix := remote + "/"
-
-	for _, line := range lines {
-		branch := strings.TrimSpace(line)
-		if branch != "" {
-			// Strip remote prefix from branch names (e.g., "origin/feature" -> "feature")
-			if after, ok := strings.CutPrefix(branch, remotePrefix); ok {
-				branch = after
-			}
-			if seen[branch] {
-				continue
-			}
+	add := func(branch string) {
+		if !seen[branch] {
 			seen[branch] = true
 			branches = append(branches, branch)
 		}
 	}
 
+	for _, line := range lines {
+		ref := strings.TrimSpace(line)
+		if branch, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			add(branch)
+			continue
+		}
+
+		remoteBranch, ok := strings.CutPrefix(ref, "refs/remotes/")
+		if !ok || strings.HasSuffix(remoteBranch, "/HEAD") {
+			continue
+		}
+		// Strip remote prefix from branch names (e.g., "origin/feature" -> "feature")
+		if _, branch, ok := strings.Cut(remoteBranch, "/"); ok {
+			add(branch)
+		}
+		if remote == "" {
+			add(remoteBranch)
+		}
+	}
+
 	return branches, nil
 }
 
+// Remotes lists the repository's remotes
+func (r *Repo) Remotes() ([]string, error) {
+	output, err := r.RunGitCommand(nil, "remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// FetchRemotes fetches a remote, or every remote if none is given
+func (r *Repo) FetchRemotes(remote string) error {
+	args := []string{"fetch", "--prune", remote}
+	if remote == "" {
+		args = []string{"fetch", "--prune", "--all"}
+	}
+	if _, err := r.RunGitCommand(nil, args...); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	return nil
+}
+
+// ResolveRemoteBranch works out which remote a branch should come from when
+// it isn't local. A remote/branch form picks the remote, otherwise every
+// remote is searched and a branch found on several is an error.
+func (r *Repo) ResolveRemoteBranch(branch, remote string) (string, string, error) {
+	if remote != "" || r.LocalBranchExists(branch) {
+		return remote, branch, nil
+	}
+
+	remotes, err := r.Remotes()
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, candidate := range remotes {
+		if after, ok := strings.CutPrefix(branch, candidate+"/"); ok && r.RemoteBranchExists(candidate, after) {
+			return candidate, after, nil
+		}
+	}
+
+	var found []string
+	for _, candidate := range remotes {
+		if r.RemoteBranchExists(candidate, branch) {
+			found = append(found, candidate)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", "", fmt.Errorf("branch '%s' does not exist locally or on any remote", branch)
+	case 1:
+		return found[0], branch, nil
+	default:
+		var choices []string
+		for _, candidate := range found {
+			choices = append(choices, candidate+"/"+branch)
+		}
+		return "", "", fmt.Errorf("branch '%s' exists on %d remotes, choose one with --remote or as remote/branch:\n  %s", branch, len(found), strings.Join(choices, "\n  "))
+	}
+}
+
 // GetWorktreePath returns the path where a worktree for the given branch should be
 func (r *Repo) GetWorktreePath(branch string) string {
 	return filepath.Join(r.WorktreesDir, branch)
@@ -191,6 +285,17 @@ func (r *Repo) BranchExists(branch string) bool {
 	return err == nil
 }
 
+// LocalBranchExists checks if a local branch exists, without resolving tags
+// or remote-tracking branches of the same name
+func (r *Repo) LocalBranchExists(branch string) bool {
+	return r.BranchExists("refs/heads/" + branch)
+}
+
+// RemoteBranchExists checks if a remote-tracking branch exists
+func (r *Repo) RemoteBranchExists(remote, branch string) bool {
+	return r.BranchExists(fmt.Sprintf("refs/remotes/%s/%s", remote, branch))
+}
+
 func (r *Repo) RunGitCommand(wt *Worktree, args ...string) ([]byte, error) {
 	if wt != nil {
 		args = append([]string{"-C", wt.Path}, args...)
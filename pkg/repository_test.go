@@ -88,3 +88,93 @@ func assertContains(t *testing.T, values []string, expected string) {
 	}
 	t.Fatalf("expected %q in %v", expected, values)
 }
+
+// setupTestRepo creates a repository with one committed file and loads it
+// from inside the main worktree
+func setupTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	repoDir := filepath.Join(t.TempDir(), "repo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+
+	runGit(t, repoDir, "init")
+	runGit(t, repoDir, "config", "user.email", "tests@example.com")
+	runGit(t, repoDir, "config", "user.name", "Tests")
+
+	if err := os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("test\n"), 0644); err != nil {
+		t.Fatalf("failed to write seed file: %v", err)
+	}
+	runGit(t, repoDir, "add", "README.md")
+	runGit(t, repoDir, "commit", "-m", "init")
+	runGit(t, repoDir, "branch", "-M", "main")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if err := os.Chdir(repoDir); err != nil {
+		t.Fatalf("failed to chdir to repo: %v", err)
+	}
+
+	return reloadTestRepo(t)
+}
+
+// reloadTestRepo reloads the repository from the current directory
+func reloadTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	repo, err := LoadRepo()
+	if err != nil {
+		t.Fatalf("LoadRepo failed: %v", err)
+	}
+	return repo
+}
+
+func TestResolveRemoteBranch_SearchesAllRemotes(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	for _, remote := range []string{"origin", "upstream"} {
+		remoteDir := filepath.Join(t.TempDir(), remote+".git")
+		runGit(t, main, "init", "--bare", remoteDir)
+		runGit(t, main, "remote", "add", remote, remoteDir)
+		runGit(t, main, "push", remote, "main:shared")
+	}
+	runGit(t, main, "push", "upstream", "main:fork-only")
+	runGit(t, main, "fetch", "--all")
+
+	remote, branch, err := repo.ResolveRemoteBranch("fork-only", "")
+	if err != nil || remote != "upstream" || branch != "fork-only" {
+		t.Fatalf("expected upstream/fork-only, got %s/%s, %v", remote, branch, err)
+	}
+
+	if _, _, err := repo.ResolveRemoteBranch("shared", ""); err == nil || !strings.Contains(err.Error(), "upstream/shared") {
+		t.Fatalf("expected an ambiguity error listing the choices, got %v", err)
+	}
+
+	remote, branch, err = repo.ResolveRemoteBranch("upstream/shared", "")
+	if err != nil || remote != "upstream" || branch != "shared" {
+		t.Fatalf("expected upstream/shared, got %s/%s, %v", remote, branch, err)
+	}
+
+	if _, _, err := repo.ResolveRemoteBranch("missing", ""); err == nil {
+		t.Fatalf("expected an error for a branch on no remote")
+	}
+
+	wt, err := repo.AddExistingBranch("fork-only", "fork-only", "upstream")
+	if err != nil {
+		t.Fatalf("AddExistingBranch failed: %v", err)
+	}
+	if wt.RemoteBranch != "upstream/fork-only" {
+		t.Fatalf("expected tracking upstream/fork-only, got %q", wt.RemoteBranch)
+	}
+
+	branches, err := repo.AllBranches("")
+	if err != nil {
+		t.Fatalf("AllBranches failed: %v", err)
+	}
+	assertContains(t, branches, "shared")
+	assertContains(t, branches, "origin/shared")
+	assertContains(t, branches, "upstream/shared")
+}
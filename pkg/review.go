@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReviewNamespace is the directory in WorktreesDir that holds review worktrees
+const ReviewNamespace = "review"
+
+// DefaultReviewTTL is how long a review worktree lives when no TTL is given
+const DefaultReviewTTL = 3 * 24 * time.Hour
+
+// expiryPostpone is how long a declined expiry prompt stays quiet
+const expiryPostpone = 24 * time.Hour
+
+// ParseTTL parses a duration such as 90m, 12h or 3d
+func ParseTTL(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid TTL '%s'", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid TTL '%s' (use e.g. 90m, 12h or 3d)", value)
+	}
+	return ttl, nil
+}
+
+// reviewName turns a ref into a worktree name
+func reviewName(ref string) string {
+	return strings.ReplaceAll(ref, "/", "-")
+}
+
+// CreateReview creates a detached-HEAD worktree for a ref under the review
+// namespace that expires after ttl
+func (r *Repo) CreateReview(ref string, ttl time.Duration) (*Worktree, error) {
+	output, err := r.RunGitCommand(nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a branch, tag or commit", ref)
+	}
+	commit := strings.TrimSpace(string(output))
+
+	// Only the review namespace matters, other worktrees may share the name
+	name := reviewName(ref)
+	worktreePath := r.GetWorktreePath(filepath.Join(ReviewNamespace, name))
+	if _, err := os.Stat(worktreePath); err == nil {
+		return nil, fmt.Errorf("review worktree already exists: %s", worktreePath)
+	}
+
+	if err := r.EnsureWorktreesDir(); err != nil {
+		return nil, fmt.Errorf("failed to create worktrees directory: %w", err)
+	}
+
+	if _, err := r.RunGitCommand(nil, "worktree", "add", "--detach", worktreePath, commit); err != nil {
+		return nil, fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	wt := &Worktree{
+		Path: worktreePath,
+		Name: name,
+	}
+
+	meta := newCreationMeta(ref)
+	meta.Expires = meta.Created.Add(ttl)
+	r.recordCreation(wt, meta)
+
+	r.applyPostCreateSetup(wt)
+
+	return wt, nil
+}
+
+// IsReview reports whether a worktree lives in the review namespace
+func (r *Repo) IsReview(wt *Worktree) bool {
+	return filepath.Dir(wt.Path) == filepath.Join(r.WorktreesDir, ReviewNamespace)
+}
+
+// ExpiredWorktrees returns the worktrees whose expiry has passed, in list order
+func (r *Repo) ExpiredWorktrees() []*Worktree {
+	var expired []*Worktree
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		if !r.IsMainWorktree(wt) && wt.Meta.Expired() {
+			expired = append(expired, wt)
+		}
+	}
+	return expired
+}
+
+// ExpiryKeepReason explains why an expired worktree shouldn't be removed
+// without asking, such as uncommitted changes or commits only its HEAD has.
+// It is empty if removing the worktree loses nothing.
+func (r *Repo) ExpiryKeepReason(wt *Worktree) string {
+	output, err := r.RunGitCommand(wt, "status", "--porcelain")
+	if err != nil {
+		return fmt.Sprintf("failed to check status: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		return "uncommitted changes"
+	}
+
+	// A branch keeps its commits, but the worktree is now used for work
+	if wt.Branch != "" {
+		return fmt.Sprintf("switched to branch %s", wt.Branch)
+	}
+
+	// Commits made on the detached HEAD would become unreachable
+	output, err = r.RunGitCommand(wt, "rev-list", "--count", "HEAD", "--not", "--branches", "--tags", "--remotes")
+	if err != nil {
+		return fmt.Sprintf("failed to check commits: %v", err)
+	}
+	if count := strings.TrimSpace(string(output)); count != "0" {
+		return fmt.Sprintf("%s commit(s) not on any branch", count)
+	}
+
+	return ""
+}
+
+// OfferExpiredCleanup asks whether to remove expired worktrees other than the
+// current one. Worktrees with work that removing them would lose are left out.
+// Declining postpones the question for a day.
+func (r *Repo) OfferExpiredCleanup() {
+	var expired []*Worktree
+	for _, wt := range r.ExpiredWorktrees() {
+		if wt == r.CurrentWorktree || time.Now().Before(wt.Meta.PromptAfter) {
+			continue
+		}
+		if r.ExpiryKeepReason(wt) == "" {
+			expired = append(expired, wt)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+
+	var names []string
+	for _, wt := range expired {
+		names = append(names, wt.Name)
+	}
+
+	if !Confirm(fmt.Sprintf("%d worktree(s) have expired: %s. Remove them?", len(expired), strings.Join(names, ", "))) {
+		for _, wt := range expired {
+			// The expiry itself is kept for 'wrk clean --expired' and list
+			meta := wt.Meta
+			meta.PromptAfter = time.Now().Add(expiryPostpone).Truncate(time.Second)
+			if err := r.SetWorktreeMeta(wt, meta); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to postpone the prompt for %s: %v\n", wt.Name, err)
+			}
+		}
+		return
+	}
+
+	for _, wt := range expired {
+		if err := r.RemoveWorktree(wt, false); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", wt.Name, err)
+			continue
+		}
+		fmt.Printf("Removed expired worktree: %s\n", wt.Name)
+	}
+	r.dropWorktrees(expired)
+}
+
+// dropWorktrees forgets worktrees that were removed during this invocation
+func (r *Repo) dropWorktrees(removed []*Worktree) {
+	gone := make(map[string]bool)
+	for _, wt := range removed {
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+			gone[wt.Path] = true
+		}
+	}
+	if len(gone) == 0 {
+		return
+	}
+
+	main, current := r.MainWorktree.Path, r.CurrentWorktree.Path
+	kept := r.Worktrees[:0]
+	for _, wt := range r.Worktrees {
+		if !gone[wt.Path] {
+			kept = append(kept, wt)
+		}
+	}
+	r.Worktrees = kept
+
+	// The slice moved, so re-point the main and current worktrees
+	for i := range r.Worktrees {
+		if r.Worktrees[i].Path == main {
+			r.MainWorktree = &r.Worktrees[i]
+		}
+		if r.Worktrees[i].Path == current {
+			r.CurrentWorktree = &r.Worktrees[i]
+		}
+	}
+}
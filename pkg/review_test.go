@@ -0,0 +1,189 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseTTL(t *testing.T) {
+	cases := map[string]time.Duration{
+		"3d":  72 * time.Hour,
+		"12h": 12 * time.Hour,
+		"90m": 90 * time.Minute,
+	}
+	for value, want := range cases {
+		got, err := ParseTTL(value)
+		if err != nil || got != want {
+			t.Fatalf("ParseTTL(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "0d", "-1h", "soon"} {
+		if _, err := ParseTTL(value); err == nil {
+			t.Fatalf("expected ParseTTL(%q) to fail", value)
+		}
+	}
+}
+
+func TestCreateReview_DetachedAndExpires(t *testing.T) {
+	repo := setupTestRepo(t)
+	runGit(t, repo.MainWorktree.Path, "branch", "feature/login")
+
+	if _, err := repo.CreateReview("feature/login", time.Hour); err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+	if _, err := repo.CreateReview("no-such-ref", time.Hour); err == nil {
+		t.Fatalf("expected CreateReview to reject an unknown ref")
+	}
+
+	repo = reloadTestRepo(t)
+	wt := repo.FindWorktreeByName("feature-login")
+	if wt == nil {
+		t.Fatalf("expected review worktree feature-login")
+	}
+	if wt.Branch != "" {
+		t.Fatalf("expected detached HEAD, got branch %q", wt.Branch)
+	}
+	if wt.Path != filepath.Join(repo.WorktreesDir, ReviewNamespace, "feature-login") || !repo.IsReview(wt) {
+		t.Fatalf("expected worktree under review/, got %s", wt.Path)
+	}
+	if wt.Meta.Base != "feature/login" || wt.Meta.Expired() {
+		t.Fatalf("unexpected metadata %+v", wt.Meta)
+	}
+	if len(repo.ExpiredWorktrees()) != 0 {
+		t.Fatalf("expected no expired worktrees yet")
+	}
+
+	meta := wt.Meta
+	meta.Expires = time.Now().Add(-time.Minute)
+	if err := repo.SetWorktreeMeta(wt, meta); err != nil {
+		t.Fatalf("SetWorktreeMeta failed: %v", err)
+	}
+	expired := repo.ExpiredWorktrees()
+	if len(expired) != 1 || expired[0] != wt {
+		t.Fatalf("expected feature-login to have expired, got %v", expired)
+	}
+
+	// Detached worktrees have no branch to delete
+	repo.Config = &Config{DeleteBranchWithWorktree: true}
+	if err := repo.RemoveWorktree(wt, false); err != nil {
+		t.Fatalf("RemoveWorktree failed: %v", err)
+	}
+	if !repo.BranchExists("feature/login") {
+		t.Fatalf("expected the reviewed branch to be kept")
+	}
+}
+
+func TestCreateReview_NameSharedWithOtherWorktree(t *testing.T) {
+	repo := setupTestRepo(t)
+	runGit(t, repo.MainWorktree.Path, "branch", "feature/login")
+	if _, err := repo.CreateNewBranch("feature-login", "feature-login"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+
+	wt, err := repo.CreateReview("feature/login", time.Hour)
+	if err != nil {
+		t.Fatalf("expected a normal worktree not to block the review, got %v", err)
+	}
+	if !repo.IsReview(wt) {
+		t.Fatalf("expected worktree under review/, got %s", wt.Path)
+	}
+	if _, err := repo.CreateReview("feature/login", time.Hour); err == nil {
+		t.Fatalf("expected a second review of the same ref to fail")
+	}
+}
+
+// expireAll marks every review worktree as expired a minute ago
+func expireAll(t *testing.T, repo *Repo) {
+	t.Helper()
+	for i := range repo.Worktrees {
+		wt := &repo.Worktrees[i]
+		if !repo.IsReview(wt) {
+			continue
+		}
+		meta := wt.Meta
+		meta.Expires = time.Now().Add(-time.Minute)
+		if err := repo.SetWorktreeMeta(wt, meta); err != nil {
+			t.Fatalf("SetWorktreeMeta failed: %v", err)
+		}
+	}
+}
+
+// answerPrompt feeds an answer to the next Confirm
+func answerPrompt(t *testing.T, answer string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "answer")
+	writeTestFile(t, path, answer+"\n")
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open answer: %v", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		file.Close()
+	})
+}
+
+func TestOfferExpiredCleanup_KeepsWorktreesWithWork(t *testing.T) {
+	repo := setupTestRepo(t)
+	reviews := map[string]*Worktree{}
+	for _, ref := range []string{"clean", "dirty", "committed"} {
+		runGit(t, repo.MainWorktree.Path, "branch", ref)
+		wt, err := repo.CreateReview(ref, time.Hour)
+		if err != nil {
+			t.Fatalf("CreateReview failed: %v", err)
+		}
+		reviews[ref] = wt
+	}
+	writeTestFile(t, filepath.Join(reviews["dirty"].Path, "README.md"), "edited\n")
+	writeTestFile(t, filepath.Join(reviews["committed"].Path, "README.md"), "edited\n")
+	runGit(t, reviews["committed"].Path, "commit", "-qam", "work on detached HEAD")
+
+	repo = reloadTestRepo(t)
+	expireAll(t, repo)
+	if reason := repo.ExpiryKeepReason(repo.FindWorktreeByName("committed")); reason != "1 commit(s) not on any branch" {
+		t.Fatalf("unexpected keep reason %q", reason)
+	}
+
+	answerPrompt(t, "y")
+	repo.OfferExpiredCleanup()
+
+	assertExists(t, reviews["clean"].Path, false)
+	assertExists(t, reviews["dirty"].Path, true)
+	assertExists(t, reviews["committed"].Path, true)
+}
+
+func TestOfferExpiredCleanup_DeclineKeepsExpiry(t *testing.T) {
+	repo := setupTestRepo(t)
+	runGit(t, repo.MainWorktree.Path, "branch", "feature")
+	wt, err := repo.CreateReview("feature", time.Hour)
+	if err != nil {
+		t.Fatalf("CreateReview failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	expireAll(t, repo)
+	expires := repo.FindWorktreeByName("feature").Meta.Expires
+
+	answerPrompt(t, "n")
+	repo.OfferExpiredCleanup()
+	assertExists(t, wt.Path, true)
+
+	repo = reloadTestRepo(t)
+	wt = repo.FindWorktreeByName("feature")
+	if !wt.Meta.Expires.Equal(expires) || len(repo.ExpiredWorktrees()) != 1 {
+		t.Fatalf("expected the expiry to be kept, got %+v", wt.Meta)
+	}
+	if !wt.Meta.PromptAfter.After(time.Now()) {
+		t.Fatalf("expected the prompt to be postponed, got %+v", wt.Meta)
+	}
+
+	// Nothing is asked until the postponement is over, so a yes isn't read
+	answerPrompt(t, "y")
+	repo.OfferExpiredCleanup()
+	assertExists(t, wt.Path, true)
+}
@@ -4,81 +4,264 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+
+	"github.com/fatih/color"
+)
+
+// SkipStrategy determines how a skipped file in a non-main worktree is tied to
+// the main worktree's version
+type SkipStrategy string
+
+const (
+	SkipSymlink  SkipStrategy = "symlink"  // Absolute symlink to main's file
+	SkipCopy     SkipStrategy = "copy"     // Independent copy of main's file
+	SkipHardlink SkipStrategy = "hardlink" // Hard link to main's file
 )
 
-// SkipFile marks a file to have its changes skipped using git skip-worktree
-// across all worktrees. For non-main worktrees, it symlinks the file to the
-// main worktree's version.
-func (r *Repo) SkipFile(file string) error {
-	// Get the absolute path from the main worktree
-	mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+// ParseSkipStrategy validates a skip strategy name
+func ParseSkipStrategy(name string) (SkipStrategy, error) {
+	switch strategy := SkipStrategy(name); strategy {
+	case SkipSymlink, SkipCopy, SkipHardlink:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown skip strategy '%s' (expected symlink, copy or hardlink)", name)
+}
+
+// DefaultSkipStrategy returns the configured global skip strategy
+func (r *Repo) DefaultSkipStrategy() SkipStrategy {
+	if r.Config == nil || r.Config.SkipStrategy == "" {
+		return SkipSymlink
+	}
+	return SkipStrategy(r.Config.SkipStrategy)
+}
+
+// SetDefaultSkipStrategy sets the global skip strategy used for files without their own
+func (r *Repo) SetDefaultSkipStrategy(strategy SkipStrategy) error {
+	if r.Config == nil {
+		r.Config = &Config{}
+	}
+	r.Config.SkipStrategy = string(strategy)
+	return r.SaveConfig()
+}
 
-	// Check if file exists in main worktree
-	if _, err := os.Stat(mainFilePath); err != nil {
-		return fmt.Errorf("file not found in main worktree: %w", err)
+// SkipStrategyFor returns the skip strategy for a file or pattern
+func (r *Repo) SkipStrategyFor(file string) SkipStrategy {
+	if r.Config != nil {
+		if strategy, ok := r.Config.SkipStrategies[file]; ok {
+			return SkipStrategy(strategy)
+		}
+	}
+	return r.DefaultSkipStrategy()
+}
+
+// setSkipStrategies records a per-file strategy for each path without saving
+func (r *Repo) setSkipStrategies(strategy SkipStrategy, paths ...string) {
+	if r.Config == nil {
+		r.Config = &Config{}
+	}
+	if r.Config.SkipStrategies == nil {
+		r.Config.SkipStrategies = make(map[string]string)
+	}
+	for _, path := range paths {
+		r.Config.SkipStrategies[path] = string(strategy)
+	}
+}
+
+// clearSkipStrategies forgets per-file strategies, reporting whether any were set
+func (r *Repo) clearSkipStrategies(paths ...string) bool {
+	if r.Config == nil {
+		return false
+	}
+	cleared := false
+	for _, path := range paths {
+		if _, ok := r.Config.SkipStrategies[path]; ok {
+			delete(r.Config.SkipStrategies, path)
+			cleared = true
+		}
 	}
+	return cleared
+}
 
-	// Check if file is tracked in git in the main worktree
-	_, err := r.RunGitCommand(r.MainWorktree, "ls-files", "--error-unmatch", file)
+// SkipFile marks the tracked files matching a path, directory or glob pattern
+// to have their changes skipped using git skip-worktree across all worktrees.
+// For non-main worktrees, each file is tied to the main worktree's version
+// using the given strategy, or the default strategy if empty.
+// Directory and glob patterns are remembered so that files added under them
+// later are skipped in new worktrees too.
+func (r *Repo) SkipFile(pattern string, strategy SkipStrategy) error {
+	files, err := r.expandTrackedFiles(r.MainWorktree, pattern)
 	if err != nil {
-		return fmt.Errorf("file is not tracked by git in main worktree: %s", file)
+		return err
 	}
 
-	var errors []string
+	if len(files) == 0 {
+		return fmt.Errorf("no files tracked by git in main worktree match: %s", pattern)
+	}
 
-	// Process each worktree
-	for _, wt := range r.Worktrees {
-		wtFilePath := filepath.Join(wt.Path, file)
+	// Per-file strategies are saved once every worktree is skipped.
+	// An explicit default replaces any per-file override.
+	var change skipConfigChange
+	if strategy != "" {
+		change.Paths = append(files, pattern)
+		if strategy != r.DefaultSkipStrategy() {
+			change.Strategy = string(strategy)
+		}
+	}
 
-		// Check if file exists in this worktree (either as file or symlink)
-		if _, err := os.Lstat(wtFilePath); err != nil {
-			if os.IsNotExist(err) {
-				errors = append(errors, fmt.Sprintf("worktree %s: file does not exist", wt.Name))
-				continue
-			}
-			errors = append(errors, fmt.Sprintf("worktree %s: failed to check file: %v", wt.Name, err))
-			continue
+	// Remember patterns that aren't a single literal file
+	if !(len(files) == 1 && files[0] == pattern) {
+		change.AddPattern = pattern
+	}
+
+	return r.skipTrackedFiles(fmt.Sprintf("skip %s", pattern), strategy, change, files...)
+}
+
+// skipTrackedFiles skips tracked files across all worktrees as a single
+// transaction, using the given strategy or each file's own if empty, and then
+// saves the config change. Worktrees that don't have a file are reported and
+// left out.
+func (r *Repo) skipTrackedFiles(operation string, strategy SkipStrategy, change skipConfigChange, files ...string) error {
+	for _, file := range files {
+		// Check if file exists in main worktree
+		mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+		if _, err := os.Stat(mainFilePath); err != nil {
+			return fmt.Errorf("%s: file not found in main worktree: %w", file, err)
 		}
+	}
 
-		// Skip the file in this worktree
-		if err := r.skipFileInWorktree(&wt, file); err != nil {
-			errors = append(errors, fmt.Sprintf("worktree %s: %v", wt.Name, err))
-			continue
+	steps, notes, err := r.planSkipSteps(true, strategy, r.Worktrees, files...)
+	if err != nil {
+		return err
+	}
+	if len(notes) > 0 {
+		color.Yellow("Warning: left out worktrees that don't have the file:\n%s\n", strings.Join(notes, "\n"))
+	}
+
+	return r.runSkipTransaction(&skipJournal{Operation: operation, Steps: steps, Config: change})
+}
+
+// expandTrackedFiles returns the files tracked in a worktree that match a
+// path, directory or glob pattern
+func (r *Repo) expandTrackedFiles(wt *Worktree, pattern string) ([]string, error) {
+	output, err := r.RunGitCommand(wt, "ls-files", "-z", "--", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed in some worktrees:\n%s", strings.Join(errors, "\n"))
+	return files, nil
+}
+
+// UnskipFile unmarks the skipped files matching a path, directory or glob
+// pattern so their changes will be tracked again across all worktrees. It
+// removes the skip-worktree flag and restores each file to its branch-specific
+// version.
+func (r *Repo) UnskipFile(pattern string) error {
+	skipped, err := r.getSkippedFilesInWorktree(r.MainWorktree, pattern)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	files := make([]string, 0, len(skipped))
+	for file := range skipped {
+		files = append(files, file)
+	}
+
+	// New worktrees would skip the files again while a pattern covers them
+	covering, err := r.coveringSkipPattern(pattern, files)
+	if err != nil {
+		return err
+	}
+	if covering != "" {
+		return fmt.Errorf("%s is skipped by pattern '%s' (unskip the pattern instead)", pattern, covering)
+	}
+
+	removedPattern := slices.Contains(r.ListSkipPatterns(), pattern)
+
+	if len(skipped) == 0 && !removedPattern {
+		return fmt.Errorf("no skipped files match: %s", pattern)
+	}
+
+	// Files added under the pattern later are only skipped in other worktrees
+	if removedPattern {
+		matched, err := r.expandTrackedFiles(r.MainWorktree, pattern)
+		if err != nil {
+			return err
+		}
+		for _, file := range matched {
+			if !skipped[file] {
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	steps, _, err := r.planSkipSteps(false, "", r.Worktrees, files...)
+	if err != nil {
+		return err
+	}
+
+	change := skipConfigChange{Paths: append(files, pattern)}
+	if removedPattern {
+		change.RemovePattern = pattern
+	}
+	return r.runSkipTransaction(&skipJournal{Operation: fmt.Sprintf("unskip %s", pattern), Steps: steps, Config: change})
 }
 
-// UnskipFile unmarks a file so its changes will be tracked again across all
-// worktrees. It removes the skip-worktree flag and restores the file to its
-// branch-specific version.
-func (r *Repo) UnskipFile(file string) error {
-	var errors []string
+// ListSkipPatterns returns the directory and glob patterns remembered by SkipFile
+func (r *Repo) ListSkipPatterns() []string {
+	if r.Config == nil {
+		return nil
+	}
+	return r.Config.Skip
+}
 
-	// Process each worktree
-	for _, wt := range r.Worktrees {
-		if err := r.unskipFileInWorktree(&wt, file); err != nil {
-			errors = append(errors, fmt.Sprintf("worktree %s: %v", wt.Name, err))
+// coveringSkipPattern returns a remembered pattern, other than the given one,
+// that matches any of the files, or an empty string if there is none
+func (r *Repo) coveringSkipPattern(pattern string, files []string) (string, error) {
+	for _, remembered := range r.ListSkipPatterns() {
+		if remembered == pattern {
 			continue
 		}
+		matched, err := r.expandTrackedFiles(r.MainWorktree, remembered)
+		if err != nil {
+			return "", err
+		}
+		for _, file := range matched {
+			if slices.Contains(files, file) {
+				return remembered, nil
+			}
+		}
 	}
+	return "", nil
+}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed in some worktrees:\n%s", strings.Join(errors, "\n"))
+// removeSkipPattern forgets a skip pattern without saving, reporting whether
+// it was present
+func (r *Repo) removeSkipPattern(pattern string) bool {
+	if r.Config == nil || !slices.Contains(r.Config.Skip, pattern) {
+		return false
 	}
 
-	return nil
+	r.Config.Skip = slices.DeleteFunc(r.Config.Skip, func(p string) bool {
+		return p == pattern
+	})
+	return true
 }
 
-// LocalSkipFile marks a file to have its changes skipped only in the current worktree.
+// LocalSkipFile marks the files matching a path, directory or glob pattern to
+// have their changes skipped only in the current worktree.
 // This does not work in the main worktree.
-func (r *Repo) LocalSkipFile(file string) error {
+func (r *Repo) LocalSkipFile(pattern string) error {
 	if r.CurrentWorktree == nil {
 		return fmt.Errorf("not in a worktree")
 	}
@@ -87,12 +270,28 @@ func (r *Repo) LocalSkipFile(file string) error {
 		return fmt.Errorf("local skip does not work in main worktree")
 	}
 
-	return r.skipFileInWorktree(r.CurrentWorktree, file)
+	files, err := r.expandTrackedFiles(r.CurrentWorktree, pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no files tracked by git match: %s", pattern)
+	}
+
+	for _, file := range files {
+		if err := r.skipFileInWorktree(r.CurrentWorktree, file); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return nil
 }
 
-// LocalUnskipFile unmarks a file so its changes will be tracked again only in the current worktree.
+// LocalUnskipFile unmarks the files matching a path, directory or glob pattern
+// so their changes will be tracked again only in the current worktree.
 // This does not work in the main worktree.
-func (r *Repo) LocalUnskipFile(file string) error {
+func (r *Repo) LocalUnskipFile(pattern string) error {
 	if r.CurrentWorktree == nil {
 		return fmt.Errorf("not in a worktree")
 	}
@@ -101,11 +300,31 @@ func (r *Repo) LocalUnskipFile(file string) error {
 		return fmt.Errorf("local unskip does not work in main worktree")
 	}
 
-	return r.unskipFileInWorktree(r.CurrentWorktree, file)
+	skipped, err := r.getSkippedFilesInWorktree(r.CurrentWorktree, pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(skipped) == 0 {
+		return fmt.Errorf("no skipped files match: %s", pattern)
+	}
+
+	for file := range skipped {
+		if err := r.unskipFileInWorktree(r.CurrentWorktree, file); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return nil
 }
 
 // skipFileInWorktree skips a file in a specific worktree
 func (r *Repo) skipFileInWorktree(wt *Worktree, file string) error {
+	return r.skipFileWithStrategy(wt, file, r.SkipStrategyFor(file))
+}
+
+// skipFileWithStrategy skips a file in a specific worktree using the given strategy
+func (r *Repo) skipFileWithStrategy(wt *Worktree, file string, strategy SkipStrategy) error {
 	wtFilePath := filepath.Join(wt.Path, file)
 
 	// Run git update-index --skip-worktree in this worktree
@@ -114,7 +333,7 @@ func (r *Repo) skipFileInWorktree(wt *Worktree, file string) error {
 		return fmt.Errorf("failed to skip-worktree: %w", err)
 	}
 
-	// If this is not the main worktree, replace file with symlink to main
+	// If this is not the main worktree, replace file with main's version
 	if !r.IsMainWorktree(wt) {
 		mainFilePath := filepath.Join(r.MainWorktree.Path, file)
 
@@ -123,12 +342,30 @@ func (r *Repo) skipFileInWorktree(wt *Worktree, file string) error {
 			return fmt.Errorf("failed to remove file: %w", err)
 		}
 
+		return placeSkippedFile(mainFilePath, wtFilePath, strategy)
+	}
+
+	return nil
+}
+
+// placeSkippedFile ties a worktree's copy of a skipped file to main's version
+// using the given strategy
+func placeSkippedFile(mainFilePath, wtFilePath string, strategy SkipStrategy) error {
+	switch strategy {
+	case SkipCopy:
+		if err := CopyPath(mainFilePath, wtFilePath); err != nil {
+			return fmt.Errorf("failed to copy file: %w", err)
+		}
+	case SkipHardlink:
+		if err := os.Link(mainFilePath, wtFilePath); err != nil {
+			return fmt.Errorf("failed to create hardlink: %w", err)
+		}
+	default:
 		// Create symlink to main worktree's file
 		if err := os.Symlink(mainFilePath, wtFilePath); err != nil {
 			return fmt.Errorf("failed to create symlink: %w", err)
 		}
 	}
-
 	return nil
 }
 
@@ -142,22 +379,22 @@ func (r *Repo) unskipFileInWorktree(wt *Worktree, file string) error {
 		return fmt.Errorf("failed to unskip: %w", err)
 	}
 
-	// If this is not the main worktree, remove symlink and copy from main
+	// If this is not the main worktree, replace any link to main with a copy.
+	// Copies made by the copy strategy are already independent.
 	if !r.IsMainWorktree(wt) {
-		// Check if file is a symlink and remove it
-		info, err := os.Lstat(wtFilePath)
-		if err != nil && !os.IsNotExist(err) {
+		mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+
+		linked, err := isLinkedToMain(wtFilePath, mainFilePath)
+		if err != nil {
 			return fmt.Errorf("failed to check file: %w", err)
 		}
 
-		// Remove symlink if it exists
-		if info.Mode()&os.ModeSymlink != 0 {
+		if linked {
 			if err := os.Remove(wtFilePath); err != nil {
-				return fmt.Errorf("failed to remove symlink: %w", err)
+				return fmt.Errorf("failed to remove link: %w", err)
 			}
 
 			// Copy the file from main worktree to this worktree
-			mainFilePath := filepath.Join(r.MainWorktree.Path, file)
 			err = CopyPath(mainFilePath, wtFilePath)
 			if err != nil {
 				return fmt.Errorf("failed to copy file: %w", err)
@@ -168,6 +405,28 @@ func (r *Repo) unskipFileInWorktree(wt *Worktree, file string) error {
 	return nil
 }
 
+// isLinkedToMain reports whether a worktree file is a symlink or a hardlink
+// to the main worktree's file
+func isLinkedToMain(wtFilePath, mainFilePath string) (bool, error) {
+	info, err := os.Lstat(wtFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return true, nil
+	}
+
+	mainInfo, err := os.Stat(mainFilePath)
+	if err != nil {
+		return false, nil
+	}
+	return os.SameFile(info, mainInfo), nil
+}
+
 // ListSkippedFiles returns a list of files marked with skip-worktree in the main worktree
 func (r *Repo) ListSkippedFiles() ([]string, error) {
 	skippedMap, err := r.getSkippedFilesInWorktree(r.MainWorktree)
@@ -209,11 +468,21 @@ func (r *Repo) PrintSkippedFiles() error {
 		currentSkipped = mainSkipped
 	}
 
-	if len(mainSkipped) == 0 && len(currentSkipped) == 0 {
+	patterns := r.ListSkipPatterns()
+
+	if len(mainSkipped) == 0 && len(currentSkipped) == 0 && len(patterns) == 0 {
 		fmt.Println("No files with skipped changes.")
 		return nil
 	}
 
+	for _, pattern := range patterns {
+		if label := r.skipStrategyLabel(pattern); label != "" {
+			fmt.Printf("%s (pattern, %s)\n", pattern, label)
+		} else {
+			fmt.Printf("%s (pattern)\n", pattern)
+		}
+	}
+
 	// Collect all unique files
 	allFiles := make(map[string]bool)
 	for file := range mainSkipped {
@@ -229,13 +498,13 @@ func (r *Repo) PrintSkippedFiles() error {
 		inCurrent := currentSkipped[file]
 
 		if r.IsMainWorktree(r.CurrentWorktree) {
-			// In main worktree, just print the file
-			fmt.Println(file)
+			// In main worktree, just print the file and any non-default strategy
+			fmt.Println(file + r.skipStrategyMarker(file))
 		} else {
 			// In other worktrees, show status
 			if inMain && inCurrent {
 				// File is skipped globally
-				fmt.Println(file)
+				fmt.Println(file + r.skipStrategyMarker(file))
 			} else if inMain && !inCurrent {
 				// File is globally skipped but locally unskipped
 				fmt.Printf("%s (locally unskipped)\n", file)
@@ -249,9 +518,28 @@ func (r *Repo) PrintSkippedFiles() error {
 	return nil
 }
 
-// getSkippedFilesInWorktree returns a map of skipped files in a specific worktree
-func (r *Repo) getSkippedFilesInWorktree(wt *Worktree) (map[string]bool, error) {
-	output, err := r.RunGitCommand(wt, "ls-files", "-v")
+// skipStrategyLabel returns the strategy name to display for a file or
+// pattern, or an empty string for the usual symlink strategy
+func (r *Repo) skipStrategyLabel(path string) string {
+	if strategy := r.SkipStrategyFor(path); strategy != SkipSymlink {
+		return string(strategy)
+	}
+	return ""
+}
+
+// skipStrategyMarker returns a display marker for a file's non-symlink strategy
+func (r *Repo) skipStrategyMarker(file string) string {
+	if label := r.skipStrategyLabel(file); label != "" {
+		return fmt.Sprintf(" (%s)", label)
+	}
+	return ""
+}
+
+// getSkippedFilesInWorktree returns a map of skipped files in a specific
+// worktree, optionally limited to those matching the given pathspecs
+func (r *Repo) getSkippedFilesInWorktree(wt *Worktree, pathspecs ...string) (map[string]bool, error) {
+	args := append([]string{"ls-files", "-v", "--"}, pathspecs...)
+	output, err := r.RunGitCommand(wt, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -270,9 +558,35 @@ func (r *Repo) getSkippedFilesInWorktree(wt *Worktree) (map[string]bool, error)
 		}
 	}
 
+	// Sparse-checkout marks the files it leaves out the same way, but a
+	// skipped file is always on disk
+	if len(skipped) > 0 && r.sparseEnabled(wt) {
+		for file := range skipped {
+			if _, err := os.Lstat(filepath.Join(wt.Path, file)); os.IsNotExist(err) {
+				delete(skipped, file)
+			}
+		}
+	}
+
 	return skipped, nil
 }
 
+// modifiedFiles returns the tracked files with unstaged changes in a worktree
+func (r *Repo) modifiedFiles(wt *Worktree) (map[string]bool, error) {
+	output, err := r.RunGitCommand(wt, "diff", "--name-only")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modified files: %w", err)
+	}
+
+	modified := make(map[string]bool)
+	for _, file := range strings.Split(string(output), "\n") {
+		if file != "" {
+			modified[file] = true
+		}
+	}
+	return modified, nil
+}
+
 // applySkipSettingsToWorktree applies all skip-worktree settings from the main worktree to a new worktree
 func (r *Repo) applySkipSettingsToWorktree(wt *Worktree) error {
 	// Don't apply to main worktree
@@ -286,17 +600,59 @@ func (r *Repo) applySkipSettingsToWorktree(wt *Worktree) error {
 		return fmt.Errorf("failed to get skipped files: %w", err)
 	}
 
-	// If no files are skipped, nothing to do
-	if len(skippedFiles) == 0 {
-		return nil
+	// Files already skipped here are set up, and local edits must not be replaced
+	wtSkipped, err := r.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		return fmt.Errorf("failed to get skipped files: %w", err)
+	}
+	modified, err := r.modifiedFiles(wt)
+	if err != nil {
+		return err
 	}
 
 	var errors []string
 
-	// Apply skip and symlink for each file
+	// Pick up files added under remembered patterns since they were skipped.
+	// They are skipped here only, so setting up a worktree leaves main alone.
+	strategiesChanged := false
+	for _, pattern := range r.ListSkipPatterns() {
+		files, err := r.expandTrackedFiles(r.MainWorktree, pattern)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("pattern %s: %v", pattern, err))
+			continue
+		}
+		for _, file := range files {
+			if skippedFiles[file] {
+				continue
+			}
+			skippedFiles[file] = true
+			if _, ok := r.Config.SkipStrategies[file]; ok {
+				continue
+			}
+			if strategy, ok := r.Config.SkipStrategies[pattern]; ok {
+				r.setSkipStrategies(SkipStrategy(strategy), file)
+				strategiesChanged = true
+			}
+		}
+	}
+	if strategiesChanged {
+		if err := r.SaveConfig(); err != nil {
+			errors = append(errors, err.Error())
+		}
+	}
+
+	// Apply skip and the file's strategy for each file
 	for file := range skippedFiles {
 		wtFilePath := filepath.Join(wt.Path, file)
 
+		if wtSkipped[file] {
+			continue
+		}
+		if modified[file] {
+			errors = append(errors, fmt.Sprintf("file %s: has local changes, left unskipped", file))
+			continue
+		}
+
 		// Check if file exists in this worktree
 		if _, err := os.Lstat(wtFilePath); err != nil {
 			if os.IsNotExist(err) {
@@ -307,7 +663,7 @@ func (r *Repo) applySkipSettingsToWorktree(wt *Worktree) error {
 			continue
 		}
 
-		// Apply skip-worktree and symlink
+		// Apply skip-worktree and link or copy from main
 		if err := r.skipFileInWorktree(wt, file); err != nil {
 			errors = append(errors, fmt.Sprintf("file %s: %v", file, err))
 			continue
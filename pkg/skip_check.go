@@ -0,0 +1,322 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// SkipStatus describes how a skipped file in the main worktree relates to git's view of it
+type SkipStatus struct {
+	File            string
+	LocallyModified bool // Working copy differs from the index
+	IndexStale      bool // Index blob differs from HEAD
+	UpstreamChanged bool // Upstream branch has a different version than HEAD
+}
+
+// NeedsRefresh reports whether upstream content has moved on from the skipped copy
+func (s SkipStatus) NeedsRefresh() bool {
+	return s.IndexStale || s.UpstreamChanged
+}
+
+// skipRefreshState records a local copy saved while a skipped file is being refreshed
+type skipRefreshState struct {
+	Base  string `yaml:"base"`  // Blob the local copy was based on
+	Local string `yaml:"local"` // Local content of the skipped file
+}
+
+// CheckSkippedFiles compares each skipped file's index blob with HEAD, the
+// upstream branch and the working copy in the main worktree
+func (r *Repo) CheckSkippedFiles() ([]SkipStatus, error) {
+	skipped, err := r.getSkippedFilesInWorktree(r.MainWorktree)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []SkipStatus
+	for file := range skipped {
+		status, err := r.skipStatus(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].File < statuses[j].File
+	})
+
+	return statuses, nil
+}
+
+// skipStatus computes the status of a single skipped file in the main worktree
+func (r *Repo) skipStatus(file string) (SkipStatus, error) {
+	status := SkipStatus{File: file}
+
+	indexBlob, err := r.gitBlob(":" + file)
+	if err != nil {
+		return status, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	headBlob, _ := r.gitBlob("HEAD:" + file)
+	status.IndexStale = indexBlob != headBlob
+
+	// Branches without an upstream, or where upstream lacks the file, can't drift
+	if upstreamBlob, err := r.gitBlob("@{upstream}:" + file); err == nil {
+		status.UpstreamChanged = upstreamBlob != headBlob
+	}
+
+	output, err := r.RunGitCommand(r.MainWorktree, "hash-object", "--", file)
+	if err == nil {
+		status.LocallyModified = strings.TrimSpace(string(output)) != indexBlob
+	}
+
+	return status, nil
+}
+
+// gitBlob resolves a revision:path expression to a blob id in the main worktree
+func (r *Repo) gitBlob(rev string) (string, error) {
+	output, err := r.RunGitCommand(r.MainWorktree, "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// PrintSkipCheck displays skipped files whose upstream content has changed.
+// Returns the number of files that need a refresh.
+func (r *Repo) PrintSkipCheck() (int, error) {
+	statuses, err := r.CheckSkippedFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, status := range statuses {
+		if !status.NeedsRefresh() {
+			continue
+		}
+		count++
+
+		var reasons []string
+		if status.IndexStale {
+			reasons = append(reasons, "index differs from HEAD")
+		}
+		if status.UpstreamChanged {
+			reasons = append(reasons, "upstream has a newer version")
+		}
+		if status.LocallyModified {
+			reasons = append(reasons, "local copy is modified, so pulls will fail")
+		}
+
+		fmt.Printf("%s %s (%s)\n", color.YellowString("!"), status.File, strings.Join(reasons, ", "))
+	}
+
+	if count > 0 {
+		fmt.Printf("Run 'wrk skip --refresh <file>' to merge upstream changes into the skipped copy.\n")
+	}
+
+	return count, nil
+}
+
+// refreshStatePath returns where a pending refresh of a skipped file is recorded
+func (r *Repo) refreshStatePath(file string) string {
+	return filepath.Join(r.WorktreesDir, ".skip-refresh", file+".yml")
+}
+
+// RefreshSkippedFile merges upstream changes into a skipped file's local copy
+// and skips it again across all worktrees.
+//
+// If only the index is stale the merge happens immediately. If the upstream
+// branch has changed the file, the local copy is saved and the file is
+// unskipped so that a pull can succeed; running the refresh again after the
+// pull merges the saved copy back in and re-skips the file.
+func (r *Repo) RefreshSkippedFile(file string) error {
+	statePath := r.refreshStatePath(file)
+
+	// Second phase: a pull has happened since the local copy was saved
+	if data, err := os.ReadFile(statePath); err == nil {
+		var state skipRefreshState
+		if err := yaml.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("failed to parse refresh state: %w", err)
+		}
+
+		headBlob, _ := r.gitBlob("HEAD:" + file)
+		if err := r.mergeAndReskip(file, state.Local, state.Base, headBlob); err != nil {
+			return err
+		}
+		return os.Remove(statePath)
+	}
+
+	skipped, err := r.getSkippedFilesInWorktree(r.MainWorktree, file)
+	if err != nil {
+		return err
+	}
+	if !skipped[file] {
+		return fmt.Errorf("file is not skipped: %s", file)
+	}
+
+	status, err := r.skipStatus(file)
+	if err != nil {
+		return err
+	}
+
+	if !status.NeedsRefresh() {
+		fmt.Printf("%s is up to date\n", file)
+		return nil
+	}
+
+	local, err := os.ReadFile(filepath.Join(r.MainWorktree.Path, file))
+	if err != nil {
+		return fmt.Errorf("failed to read local copy: %w", err)
+	}
+
+	indexBlob, err := r.gitBlob(":" + file)
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if !status.UpstreamChanged {
+		headBlob, _ := r.gitBlob("HEAD:" + file)
+		return r.mergeAndReskip(file, string(local), indexBlob, headBlob)
+	}
+
+	// Save the local copy, then unskip so the pull can update the file
+	data, err := yaml.Marshal(skipRefreshState{Base: indexBlob, Local: string(local)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0755); err != nil {
+		return fmt.Errorf("failed to create refresh state directory: %w", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write refresh state: %w", err)
+	}
+
+	if err := r.restoreSkippedFile(file); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is temporarily unskipped and your local copy has been saved.\n", file)
+	fmt.Printf("Pull in the main worktree, then run 'wrk skip --refresh %s' again to merge and re-skip it.\n", file)
+	return nil
+}
+
+// restoreSkippedFile unskips a file across all worktrees and resets the main
+// worktree's copy to HEAD
+func (r *Repo) restoreSkippedFile(file string) error {
+	if _, err := r.RunGitCommand(r.MainWorktree, "update-index", "--no-skip-worktree", file); err != nil {
+		return fmt.Errorf("failed to unskip: %w", err)
+	}
+	if _, err := r.RunGitCommand(r.MainWorktree, "checkout", "HEAD", "--", file); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+
+	var errors []string
+	for _, wt := range r.Worktrees {
+		if r.IsMainWorktree(&wt) {
+			continue
+		}
+		if err := r.unskipFileInWorktree(&wt, file); err != nil {
+			errors = append(errors, fmt.Sprintf("worktree %s: %v", wt.Name, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed in some worktrees:\n%s", strings.Join(errors, "\n"))
+	}
+
+	return nil
+}
+
+// mergeAndReskip three-way merges a local copy onto a newer blob, writes the
+// result to the main worktree and skips the file again everywhere
+func (r *Repo) mergeAndReskip(file, local, baseBlob, theirsBlob string) error {
+	merged, conflicts, err := r.mergeFileContent(local, baseBlob, theirsBlob)
+	if err != nil {
+		return err
+	}
+
+	// Bring the index up to date before re-skipping
+	if err := r.restoreSkippedFile(file); err != nil {
+		return err
+	}
+
+	mainFilePath := filepath.Join(r.MainWorktree.Path, file)
+	info, err := os.Stat(mainFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to check file: %w", err)
+	}
+	if err := os.WriteFile(mainFilePath, []byte(merged), info.Mode()); err != nil {
+		return fmt.Errorf("failed to write merged file: %w", err)
+	}
+
+	if err := r.skipTrackedFiles(fmt.Sprintf("refresh %s", file), "", skipConfigChange{}, file); err != nil {
+		return err
+	}
+
+	if conflicts {
+		color.Yellow("%s was re-skipped with conflict markers, resolve them in the main worktree\n", file)
+	} else {
+		fmt.Printf("%s refreshed and skipped again\n", file)
+	}
+
+	return nil
+}
+
+// mergeFileContent runs git merge-file on a local copy against two blobs
+func (r *Repo) mergeFileContent(local, baseBlob, theirsBlob string) (string, bool, error) {
+	dir, err := os.MkdirTemp("", "wrk-merge-")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	paths := map[string]string{
+		"local":    filepath.Join(dir, "local"),
+		"base":     filepath.Join(dir, "base"),
+		"upstream": filepath.Join(dir, "upstream"),
+	}
+
+	if err := os.WriteFile(paths["local"], []byte(local), 0644); err != nil {
+		return "", false, err
+	}
+	for name, blob := range map[string]string{"base": baseBlob, "upstream": theirsBlob} {
+		content := []byte{}
+		if blob != "" {
+			content, err = r.RunGitCommand(r.MainWorktree, "cat-file", "blob", blob)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to read %s blob: %w", name, err)
+			}
+		}
+		if err := os.WriteFile(paths[name], content, 0644); err != nil {
+			return "", false, err
+		}
+	}
+
+	if GlobalFlags.Verbose {
+		fmt.Fprintf(os.Stderr, "Running: git merge-file -p %s %s %s\n", paths["local"], paths["base"], paths["upstream"])
+	}
+	cmd := exec.Command("git", "merge-file", "-p",
+		"-L", "local", "-L", "base", "-L", "upstream",
+		paths["local"], paths["base"], paths["upstream"])
+	output, err := cmd.Output()
+
+	// A positive exit code is the number of conflicts
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return string(output), true, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to merge: %w", err)
+	}
+
+	return string(output), false, nil
+}
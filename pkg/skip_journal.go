@@ -0,0 +1,416 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// skipStep is a single skip or unskip of one file in one worktree, together
+// with what is needed to put the worktree back the way it was
+type skipStep struct {
+	Worktree   string `yaml:"worktree"`             // Worktree path
+	File       string `yaml:"file"`                 // File relative to the worktree
+	Skip       bool   `yaml:"skip"`                 // True to skip, false to unskip
+	WasSkipped bool   `yaml:"wasSkipped"`           // Skip-worktree flag before the step
+	Missing    bool   `yaml:"missing,omitempty"`    // File did not exist before the step
+	LinkTarget string `yaml:"linkTarget,omitempty"` // Previous symlink target
+	Hardlinked bool   `yaml:"hardlinked,omitempty"` // File was a hardlink to main's file
+	Backup     string `yaml:"backup,omitempty"`     // Backup copy of a regular file
+	Strategy   string `yaml:"strategy,omitempty"`   // Skip strategy, so a resumed run uses the same one
+	Done       bool   `yaml:"done"`
+}
+
+// skipConfigChange is the config update a skip or unskip saves once every
+// worktree is done
+type skipConfigChange struct {
+	AddPattern    string   `yaml:"addPattern,omitempty"`    // Pattern to remember
+	RemovePattern string   `yaml:"removePattern,omitempty"` // Pattern to forget
+	Strategy      string   `yaml:"strategy,omitempty"`      // Per-file strategy for Paths, empty to clear it
+	Paths         []string `yaml:"paths,omitempty"`         // Files and patterns whose strategy changes
+}
+
+// skipJournal records a multi-worktree skip or unskip so an interrupted run
+// can be resumed or undone
+type skipJournal struct {
+	Operation string           `yaml:"operation"`
+	Steps     []skipStep       `yaml:"steps"`
+	Config    skipConfigChange `yaml:"config,omitempty"`
+}
+
+// journalDir returns the directory holding the skip journal and its backups
+func (r *Repo) journalDir() string {
+	return filepath.Join(r.WorktreesDir, ".skip-journal")
+}
+
+func (r *Repo) journalPath() string {
+	return filepath.Join(r.journalDir(), "journal.yml")
+}
+
+// HasSkipJournal reports whether an interrupted skip or unskip needs resuming or undoing
+func (r *Repo) HasSkipJournal() bool {
+	_, err := os.Stat(r.journalPath())
+	return err == nil
+}
+
+// loadSkipJournal reads the journal of an interrupted run
+func (r *Repo) loadSkipJournal() (*skipJournal, error) {
+	data, err := os.ReadFile(r.journalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no interrupted skip operation to resume or undo")
+		}
+		return nil, fmt.Errorf("failed to read skip journal: %w", err)
+	}
+
+	var journal skipJournal
+	if err := yaml.Unmarshal(data, &journal); err != nil {
+		return nil, fmt.Errorf("failed to parse skip journal: %w", err)
+	}
+
+	return &journal, nil
+}
+
+// saveSkipJournal writes the journal so progress survives an interruption
+func (r *Repo) saveSkipJournal(journal *skipJournal) error {
+	data, err := yaml.Marshal(journal)
+	if err != nil {
+		return fmt.Errorf("failed to marshal skip journal: %w", err)
+	}
+
+	if err := os.WriteFile(r.journalPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write skip journal: %w", err)
+	}
+
+	return nil
+}
+
+// planSkipSteps builds the steps to skip or unskip files in the given
+// worktrees, grouped by worktree. A worktree is left out for a file it doesn't
+// track or doesn't have on disk, and for skip each such case is returned as a
+// note for the caller to report. Unskip also leaves out worktrees where the
+// file isn't skipped. An empty strategy uses each file's own.
+func (r *Repo) planSkipSteps(skip bool, strategy SkipStrategy, worktrees []Worktree, files ...string) ([]skipStep, []string, error) {
+	var steps []skipStep
+	var notes []string
+	for _, wt := range worktrees {
+		if _, err := os.Stat(wt.Path); err != nil {
+			continue
+		}
+
+		index, err := r.indexFlags(&wt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, file := range files {
+			skipped, tracked := index[file]
+			if !tracked {
+				if skip {
+					notes = append(notes, fmt.Sprintf("worktree %s: %s is not tracked", wt.Name, file))
+				}
+				continue
+			}
+			if !r.IsMainWorktree(&wt) {
+				if _, err := os.Lstat(filepath.Join(wt.Path, file)); os.IsNotExist(err) {
+					if skip {
+						notes = append(notes, fmt.Sprintf("worktree %s: %s does not exist", wt.Name, file))
+					}
+					continue
+				} else if err != nil {
+					return nil, nil, fmt.Errorf("failed to check %s: %w", filepath.Join(wt.Path, file), err)
+				}
+			}
+			if !skip && !skipped {
+				continue
+			}
+
+			step := skipStep{
+				Worktree:   wt.Path,
+				File:       file,
+				Skip:       skip,
+				WasSkipped: skipped,
+			}
+			if skip {
+				step.Strategy = string(strategy)
+				if strategy == "" {
+					step.Strategy = string(r.SkipStrategyFor(file))
+				}
+			}
+			steps = append(steps, step)
+		}
+	}
+	return steps, notes, nil
+}
+
+// indexFlags returns every file tracked in a worktree, mapped to whether it
+// has the skip-worktree flag
+func (r *Repo) indexFlags(wt *Worktree) (map[string]bool, error) {
+	output, err := r.RunGitCommand(wt, "ls-files", "-v", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	flags := make(map[string]bool)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Each entry is a tag, a space and the path
+		if len(entry) > 2 {
+			flags[entry[2:]] = entry[0] == 'S'
+		}
+	}
+	return flags, nil
+}
+
+// runSkipTransaction applies a journal's steps across worktrees and then its
+// config change. Every file that is about to be replaced is backed up first,
+// and if any step fails all worktrees are rolled back to their prior state.
+func (r *Repo) runSkipTransaction(journal *skipJournal) error {
+	if err := r.startSkipJournal(journal); err != nil {
+		return err
+	}
+	return r.executeSkipJournal(journal)
+}
+
+// startSkipJournal backs up every file the journal's steps will replace and
+// writes the journal
+func (r *Repo) startSkipJournal(journal *skipJournal) error {
+	if r.HasSkipJournal() {
+		return fmt.Errorf("an interrupted skip operation exists, run 'wrk skip --resume' or 'wrk skip --undo' first")
+	}
+
+	backupDir := filepath.Join(r.journalDir(), "backup")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	// Back up everything up front so nothing changes if a worktree can't take part
+	var errors []string
+	for i := range journal.Steps {
+		step := &journal.Steps[i]
+		if err := r.backupSkipStep(step, filepath.Join(backupDir, strconv.Itoa(i))); err != nil {
+			errors = append(errors, fmt.Sprintf("worktree %s: %s: %v", filepath.Base(step.Worktree), step.File, err))
+		} else if step.Skip && step.Missing {
+			errors = append(errors, fmt.Sprintf("worktree %s: %s: file does not exist", filepath.Base(step.Worktree), step.File))
+		}
+	}
+
+	if len(errors) > 0 {
+		os.RemoveAll(r.journalDir())
+		return fmt.Errorf("nothing was changed:\n%s", strings.Join(errors, "\n"))
+	}
+
+	if err := r.saveSkipJournal(journal); err != nil {
+		os.RemoveAll(r.journalDir())
+		return err
+	}
+
+	return nil
+}
+
+// executeSkipJournal runs every step not yet done, rolling back on failure,
+// then saves the config change
+func (r *Repo) executeSkipJournal(journal *skipJournal) error {
+	for i := range journal.Steps {
+		step := &journal.Steps[i]
+		if step.Done {
+			continue
+		}
+
+		if err := r.applySkipStep(step); err != nil {
+			stepErr := fmt.Errorf("worktree %s: %s: %w", filepath.Base(step.Worktree), step.File, err)
+			if rollbackErr := r.rollbackSkipJournal(journal); rollbackErr != nil {
+				return fmt.Errorf("%v\nrollback also failed, run 'wrk skip --undo' to retry:\n%v", stepErr, rollbackErr)
+			}
+			return fmt.Errorf("%w\nall worktrees were rolled back", stepErr)
+		}
+		step.Done = true
+
+		// Steps are safe to repeat, so progress is saved once per worktree
+		if i+1 == len(journal.Steps) || journal.Steps[i+1].Worktree != step.Worktree {
+			if err := r.saveSkipJournal(journal); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := r.applySkipConfigChange(journal.Config); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(r.journalDir())
+}
+
+// applySkipConfigChange saves the patterns and per-file strategies changed by
+// a finished skip or unskip
+func (r *Repo) applySkipConfigChange(change skipConfigChange) error {
+	if change.AddPattern == "" && change.RemovePattern == "" && len(change.Paths) == 0 {
+		return nil
+	}
+	if r.Config == nil {
+		r.Config = &Config{}
+	}
+
+	if change.Strategy != "" {
+		r.setSkipStrategies(SkipStrategy(change.Strategy), change.Paths...)
+	} else {
+		r.clearSkipStrategies(change.Paths...)
+	}
+
+	if change.AddPattern != "" && !slices.Contains(r.ListSkipPatterns(), change.AddPattern) {
+		r.Config.Skip = append(r.Config.Skip, change.AddPattern)
+	}
+	if change.RemovePattern != "" {
+		r.removeSkipPattern(change.RemovePattern)
+	}
+
+	return r.SaveConfig()
+}
+
+// backupSkipStep records the state of a file before a step touches it
+func (r *Repo) backupSkipStep(step *skipStep, backupPath string) error {
+	wt := &Worktree{Path: step.Worktree}
+	wtFilePath := filepath.Join(step.Worktree, step.File)
+
+	// Skipping never changes the main worktree's file, only its flag
+	if r.IsMainWorktree(wt) {
+		return nil
+	}
+
+	info, err := os.Lstat(wtFilePath)
+	if os.IsNotExist(err) {
+		step.Missing = true
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %w", wtFilePath, err)
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		step.LinkTarget, err = os.Readlink(wtFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read symlink %s: %w", wtFilePath, err)
+		}
+		return nil
+	}
+
+	mainFilePath := filepath.Join(r.MainWorktree.Path, step.File)
+	if linked, _ := isLinkedToMain(wtFilePath, mainFilePath); linked {
+		step.Hardlinked = true
+		return nil
+	}
+
+	if err := CopyPath(wtFilePath, backupPath); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", wtFilePath, err)
+	}
+	step.Backup = backupPath
+	return nil
+}
+
+// applySkipStep performs a single skip or unskip
+func (r *Repo) applySkipStep(step *skipStep) error {
+	wt := &Worktree{Path: step.Worktree}
+
+	if step.Skip {
+		if step.Missing {
+			return fmt.Errorf("file does not exist")
+		}
+		strategy := SkipStrategy(step.Strategy)
+		if strategy == "" {
+			strategy = r.SkipStrategyFor(step.File)
+		}
+		return r.skipFileWithStrategy(wt, step.File, strategy)
+	}
+	return r.unskipFileInWorktree(wt, step.File)
+}
+
+// restoreSkipStep puts a file and its skip-worktree flag back as they were
+// before the step. It is safe to run on steps that never started.
+func (r *Repo) restoreSkipStep(step *skipStep) error {
+	wt := &Worktree{Path: step.Worktree}
+	wtFilePath := filepath.Join(step.Worktree, step.File)
+
+	// Skipping never changes the main worktree's file, only its flag
+	if !r.IsMainWorktree(wt) {
+		if err := os.Remove(wtFilePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove file: %w", err)
+		}
+	}
+
+	switch {
+	case r.IsMainWorktree(wt):
+	case step.Missing:
+		// Nothing to restore
+	case step.LinkTarget != "":
+		if err := os.Symlink(step.LinkTarget, wtFilePath); err != nil {
+			return fmt.Errorf("failed to restore symlink: %w", err)
+		}
+	case step.Hardlinked:
+		if err := os.Link(filepath.Join(r.MainWorktree.Path, step.File), wtFilePath); err != nil {
+			return fmt.Errorf("failed to restore hardlink: %w", err)
+		}
+	case step.Backup != "":
+		if err := CopyPath(step.Backup, wtFilePath); err != nil {
+			return fmt.Errorf("failed to restore backup: %w", err)
+		}
+	}
+
+	// Leave the index alone if the step never got as far as the flag
+	skipped, err := r.getSkippedFilesInWorktree(wt, step.File)
+	if err != nil {
+		return err
+	}
+	if skipped[step.File] == step.WasSkipped {
+		return nil
+	}
+
+	flag := "--no-skip-worktree"
+	if step.WasSkipped {
+		flag = "--skip-worktree"
+	}
+	if _, err := r.RunGitCommand(wt, "update-index", flag, step.File); err != nil {
+		return fmt.Errorf("failed to restore skip-worktree flag: %w", err)
+	}
+
+	return nil
+}
+
+// rollbackSkipJournal restores every step in reverse order and removes the journal
+func (r *Repo) rollbackSkipJournal(journal *skipJournal) error {
+	var errors []string
+	for i := len(journal.Steps) - 1; i >= 0; i-- {
+		step := &journal.Steps[i]
+		if err := r.restoreSkipStep(step); err != nil {
+			errors = append(errors, fmt.Sprintf("  worktree %s: %s: %v", filepath.Base(step.Worktree), step.File, err))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "\n"))
+	}
+
+	return os.RemoveAll(r.journalDir())
+}
+
+// ResumeSkipJournal finishes an interrupted skip or unskip, including its
+// config change, returning its description
+func (r *Repo) ResumeSkipJournal() (string, error) {
+	journal, err := r.loadSkipJournal()
+	if err != nil {
+		return "", err
+	}
+	return journal.Operation, r.executeSkipJournal(journal)
+}
+
+// UndoSkipJournal rolls back an interrupted skip or unskip, returning its description
+func (r *Repo) UndoSkipJournal() (string, error) {
+	journal, err := r.loadSkipJournal()
+	if err != nil {
+		return "", err
+	}
+	return journal.Operation, r.rollbackSkipJournal(journal)
+}
@@ -0,0 +1,542 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSkipFile_PatternAppliesToLaterFiles(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	if err := os.MkdirAll(filepath.Join(main, "config"), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "config", "a.local.json"), "a\n")
+	writeTestFile(t, filepath.Join(main, "config", "shared.json"), "shared\n")
+	runGit(t, main, "add", "config")
+	runGit(t, main, "commit", "-m", "config")
+
+	if err := repo.SkipFile("config/*.local.json", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	skipped, err := repo.ListSkippedFiles()
+	if err != nil {
+		t.Fatalf("ListSkippedFiles failed: %v", err)
+	}
+	assertContains(t, skipped, "config/a.local.json")
+	if len(skipped) != 1 {
+		t.Fatalf("expected only the matching file to be skipped, got %v", skipped)
+	}
+	assertContains(t, repo.ListSkipPatterns(), "config/*.local.json")
+
+	// A file added later under the pattern is picked up by new worktrees
+	writeTestFile(t, filepath.Join(main, "config", "b.local.json"), "b\n")
+	runGit(t, main, "add", "config/b.local.json")
+	runGit(t, main, "commit", "-m", "more config")
+
+	repo = reloadTestRepo(t)
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	for _, file := range []string{"config/a.local.json", "config/b.local.json"} {
+		info, err := os.Lstat(filepath.Join(wt.Path, file))
+		if err != nil {
+			t.Fatalf("expected %s in new worktree: %v", file, err)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			t.Fatalf("expected %s to be symlinked in new worktree", file)
+		}
+	}
+
+	repo = reloadTestRepo(t)
+	if err := repo.UnskipFile("config/*.local.json"); err != nil {
+		t.Fatalf("UnskipFile failed: %v", err)
+	}
+	skipped, err = repo.ListSkippedFiles()
+	if err != nil {
+		t.Fatalf("ListSkippedFiles failed: %v", err)
+	}
+	if len(skipped) != 0 || len(repo.ListSkipPatterns()) != 0 {
+		t.Fatalf("expected nothing skipped, got files %v patterns %v", skipped, repo.ListSkipPatterns())
+	}
+}
+
+func TestSkipFile_PatternLeavesMainAndRefusesFileUnskip(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	if err := os.MkdirAll(filepath.Join(main, "config"), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "config", "a.yml"), "a\n")
+	runGit(t, main, "add", "config")
+	runGit(t, main, "commit", "-m", "config")
+
+	if err := repo.SkipFile("config/*", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	if err := repo.UnskipFile("config/a.yml"); err == nil {
+		t.Fatalf("expected unskipping a file under a remembered pattern to fail")
+	}
+
+	writeTestFile(t, filepath.Join(main, "config", "b.yml"), "b\n")
+	runGit(t, main, "add", "config/b.yml")
+	runGit(t, main, "commit", "-m", "more config")
+
+	repo = reloadTestRepo(t)
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	mainSkipped, err := repo.getSkippedFilesInWorktree(repo.MainWorktree)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if mainSkipped["config/b.yml"] {
+		t.Fatalf("expected creating a worktree to leave main's index alone")
+	}
+	wtSkipped, err := repo.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if !wtSkipped["config/b.yml"] {
+		t.Fatalf("expected config/b.yml to be skipped in the new worktree")
+	}
+
+	repo = reloadTestRepo(t)
+	if err := repo.UnskipFile("config/*"); err != nil {
+		t.Fatalf("UnskipFile failed: %v", err)
+	}
+	wtSkipped, err = repo.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if len(wtSkipped) != 0 {
+		t.Fatalf("expected nothing skipped in the worktree, got %v", wtSkipped)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func TestCheckAndRefreshSkippedFile(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	// Publish to a bare remote and keep a second clone to push upstream changes
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+	writeTestFile(t, filepath.Join(main, "settings.txt"), "one\ntwo\nthree\n")
+	runGit(t, main, "add", "settings.txt")
+	runGit(t, main, "commit", "-m", "settings")
+	runGit(t, main, "push", "-u", "origin", "main")
+
+	otherDir := filepath.Join(t.TempDir(), "other")
+	runGit(t, main, "clone", "-b", "main", remoteDir, otherDir)
+	runGit(t, otherDir, "config", "user.email", "tests@example.com")
+	runGit(t, otherDir, "config", "user.name", "Tests")
+
+	if err := repo.SkipFile("settings.txt", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "settings.txt"), "ONE\ntwo\nthree\n")
+
+	writeTestFile(t, filepath.Join(otherDir, "settings.txt"), "one\ntwo\nTHREE\n")
+	runGit(t, otherDir, "commit", "-am", "upstream edit")
+	runGit(t, otherDir, "push", "origin", "main")
+	runGit(t, main, "fetch", "origin")
+
+	statuses, err := repo.CheckSkippedFiles()
+	if err != nil {
+		t.Fatalf("CheckSkippedFiles failed: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].UpstreamChanged || !statuses[0].LocallyModified {
+		t.Fatalf("expected upstream change and local modification, got %+v", statuses)
+	}
+
+	// First refresh saves the local copy and unskips so the pull can succeed
+	if err := repo.RefreshSkippedFile("settings.txt"); err != nil {
+		t.Fatalf("RefreshSkippedFile failed: %v", err)
+	}
+	runGit(t, main, "pull", "--ff-only")
+
+	// Second refresh merges the saved copy and skips again
+	if err := repo.RefreshSkippedFile("settings.txt"); err != nil {
+		t.Fatalf("RefreshSkippedFile after pull failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(main, "settings.txt"))
+	if err != nil {
+		t.Fatalf("failed to read merged file: %v", err)
+	}
+	if string(content) != "ONE\ntwo\nTHREE\n" {
+		t.Fatalf("expected merged content, got %q", content)
+	}
+
+	statuses, err = repo.CheckSkippedFiles()
+	if err != nil {
+		t.Fatalf("CheckSkippedFiles failed: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].NeedsRefresh() {
+		t.Fatalf("expected file to be skipped and up to date, got %+v", statuses)
+	}
+}
+
+func TestSkipFile_Strategies(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, "copied.txt"), "copied\n")
+	writeTestFile(t, filepath.Join(main, "linked.txt"), "linked\n")
+	runGit(t, main, "add", ".")
+	runGit(t, main, "commit", "-m", "files")
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+
+	if err := repo.SkipFile("copied.txt", SkipCopy); err != nil {
+		t.Fatalf("SkipFile copy failed: %v", err)
+	}
+	if err := repo.SkipFile("linked.txt", SkipHardlink); err != nil {
+		t.Fatalf("SkipFile hardlink failed: %v", err)
+	}
+
+	copied := filepath.Join(wt.Path, "copied.txt")
+	info, err := os.Lstat(copied)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected regular file for copy strategy, got %v %v", info, err)
+	}
+	if linked, _ := isLinkedToMain(copied, filepath.Join(main, "copied.txt")); linked {
+		t.Fatalf("expected copy to be independent of main")
+	}
+
+	linkedPath := filepath.Join(wt.Path, "linked.txt")
+	if linked, _ := isLinkedToMain(linkedPath, filepath.Join(main, "linked.txt")); !linked {
+		t.Fatalf("expected hardlink to main")
+	}
+
+	if got := repo.SkipStrategyFor("copied.txt"); got != SkipCopy {
+		t.Fatalf("expected copy strategy to be recorded, got %q", got)
+	}
+
+	// Skipping again with the default strategy drops the override
+	if err := repo.SkipFile("copied.txt", repo.DefaultSkipStrategy()); err != nil {
+		t.Fatalf("SkipFile default failed: %v", err)
+	}
+	if got := repo.SkipStrategyFor("copied.txt"); got != SkipSymlink {
+		t.Fatalf("expected copy override to be cleared, got %q", got)
+	}
+	if _, ok := repo.Config.SkipStrategies["copied.txt"]; ok {
+		t.Fatalf("expected no per-file strategy for copied.txt")
+	}
+	if info, err := os.Lstat(copied); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected copied.txt to be symlinked again, got %v %v", info, err)
+	}
+
+	if err := repo.UnskipFile("linked.txt"); err != nil {
+		t.Fatalf("UnskipFile failed: %v", err)
+	}
+	if linked, _ := isLinkedToMain(linkedPath, filepath.Join(main, "linked.txt")); linked {
+		t.Fatalf("expected unskip to break the hardlink")
+	}
+	if got := repo.SkipStrategyFor("linked.txt"); got != SkipSymlink {
+		t.Fatalf("expected strategy to be forgotten after unskip, got %q", got)
+	}
+}
+
+func TestSkipFile_RollsBackOnFailure(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, "local.conf"), "main\n")
+	runGit(t, main, "add", "local.conf")
+	runGit(t, main, "commit", "-m", "conf")
+
+	tracked, err := repo.CreateNewBranch("a-tracked", "a-tracked")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+
+	// Another git process holds this worktree's index, so skipping fails
+	locked, err := repo.CreateNewBranch("b-locked", "b-locked")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	lock := strings.TrimSpace(gitOutput(t, locked.Path, "rev-parse", "--path-format=absolute", "--git-path", "index.lock"))
+	writeTestFile(t, lock, "")
+
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("local.conf", ""); err == nil {
+		t.Fatalf("expected SkipFile to fail")
+	}
+
+	info, err := os.Lstat(filepath.Join(tracked.Path, "local.conf"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected a regular file after rollback, got %v %v", info, err)
+	}
+	for _, wt := range []*Worktree{repo.MainWorktree, tracked} {
+		skipped, err := repo.getSkippedFilesInWorktree(wt)
+		if err != nil {
+			t.Fatalf("failed to list skipped files: %v", err)
+		}
+		if len(skipped) != 0 {
+			t.Fatalf("expected no skipped files in %s after rollback, got %v", wt.Path, skipped)
+		}
+	}
+	if repo.HasSkipJournal() {
+		t.Fatalf("expected journal to be removed after rollback")
+	}
+}
+
+func TestSkipFile_LeavesOutWorktreesWithoutFile(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, "local.conf"), "main\n")
+	runGit(t, main, "add", "local.conf")
+	runGit(t, main, "commit", "-m", "conf")
+
+	tracked, err := repo.CreateNewBranch("tracked", "tracked")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	untracked, err := repo.CreateNewBranch("untracked", "untracked")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	runGit(t, untracked.Path, "rm", "--cached", "-q", "local.conf")
+	runGit(t, untracked.Path, "commit", "-m", "untrack")
+	deleted, err := repo.CreateNewBranch("deleted", "deleted")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(deleted.Path, "local.conf")); err != nil {
+		t.Fatalf("failed to delete file: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("local.conf", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(tracked.Path, "local.conf"))
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected a symlink where the file is tracked, got %v %v", info, err)
+	}
+	info, err = os.Lstat(filepath.Join(untracked.Path, "local.conf"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected the untracked file to be left alone, got %v %v", info, err)
+	}
+	assertExists(t, filepath.Join(deleted.Path, "local.conf"), false)
+}
+
+func TestUndoSkipJournal_RestoresInterruptedRun(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+
+	// Simulate a run interrupted after changing only the feature worktree
+	journal := &skipJournal{
+		Operation: "skip README.md",
+	}
+	steps, _, err := repo.planSkipSteps(true, "", []Worktree{*repo.MainWorktree, *wt}, "README.md")
+	if err != nil {
+		t.Fatalf("planSkipSteps failed: %v", err)
+	}
+	journal.Steps = steps
+	backupDir := filepath.Join(repo.journalDir(), "backup")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatalf("failed to create backup dir: %v", err)
+	}
+	for i := range journal.Steps {
+		if err := repo.backupSkipStep(&journal.Steps[i], filepath.Join(backupDir, "step")); err != nil {
+			t.Fatalf("backupSkipStep failed: %v", err)
+		}
+	}
+	if err := repo.applySkipStep(&journal.Steps[1]); err != nil {
+		t.Fatalf("applySkipStep failed: %v", err)
+	}
+	journal.Steps[1].Done = true
+	if err := repo.saveSkipJournal(journal); err != nil {
+		t.Fatalf("saveSkipJournal failed: %v", err)
+	}
+
+	if err := repo.SkipFile("README.md", ""); err == nil {
+		t.Fatalf("expected SkipFile to refuse while a journal exists")
+	}
+
+	if _, err := repo.UndoSkipJournal(); err != nil {
+		t.Fatalf("UndoSkipJournal failed: %v", err)
+	}
+
+	info, err := os.Lstat(filepath.Join(wt.Path, "README.md"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected a regular file after undo, got %v %v", info, err)
+	}
+	skipped, err := repo.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if len(skipped) != 0 || repo.HasSkipJournal() {
+		t.Fatalf("expected clean state after undo, got skipped %v", skipped)
+	}
+}
+
+func TestUnskipFile_IgnoresUntrackedAndMissingWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	writeTestFile(t, filepath.Join(main, "local.conf"), "main\n")
+	runGit(t, main, "add", "local.conf")
+	runGit(t, main, "commit", "-m", "conf")
+
+	untracked, err := repo.CreateNewBranch("untracked", "untracked")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	gone, err := repo.CreateNewBranch("gone", "gone")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("local.conf", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	// One worktree stops tracking the file, another is deleted without git knowing
+	runGit(t, untracked.Path, "update-index", "--no-skip-worktree", "local.conf")
+	runGit(t, untracked.Path, "rm", "--cached", "-q", "local.conf")
+	runGit(t, untracked.Path, "commit", "-m", "untrack")
+	if err := os.RemoveAll(gone.Path); err != nil {
+		t.Fatalf("failed to remove worktree: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if err := repo.UnskipFile("local.conf"); err != nil {
+		t.Fatalf("UnskipFile failed: %v", err)
+	}
+	skipped, err := repo.getSkippedFilesInWorktree(repo.MainWorktree)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("expected local.conf to be unskipped in main, got %v", skipped)
+	}
+}
+
+func TestResumeSkipJournal_UsesRecordedStrategy(t *testing.T) {
+	repo := setupTestRepo(t)
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+
+	// Plan a copy-strategy skip and interrupt it before any step runs
+	journal := &skipJournal{
+		Operation: "skip README.md",
+	}
+	steps, _, err := repo.planSkipSteps(true, SkipCopy, []Worktree{*repo.MainWorktree, *wt}, "README.md")
+	if err != nil {
+		t.Fatalf("planSkipSteps failed: %v", err)
+	}
+	journal.Steps = steps
+	backupDir := filepath.Join(repo.journalDir(), "backup")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatalf("failed to create backup dir: %v", err)
+	}
+	for i := range journal.Steps {
+		if err := repo.backupSkipStep(&journal.Steps[i], filepath.Join(backupDir, strconv.Itoa(i))); err != nil {
+			t.Fatalf("backupSkipStep failed: %v", err)
+		}
+	}
+	if err := repo.saveSkipJournal(journal); err != nil {
+		t.Fatalf("saveSkipJournal failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if _, err := repo.ResumeSkipJournal(); err != nil {
+		t.Fatalf("ResumeSkipJournal failed: %v", err)
+	}
+	info, err := os.Lstat(filepath.Join(wt.Path, "README.md"))
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Fatalf("expected a copy after resume, got %v %v", info, err)
+	}
+}
+
+func TestResumeSkipJournal_SavesConfigChange(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	if err := os.MkdirAll(filepath.Join(main, "config"), 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, "config", "a.yml"), "a\n")
+	runGit(t, main, "add", "config")
+	runGit(t, main, "commit", "-m", "config")
+
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	if err := repo.SkipFile("config", SkipCopy); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+
+	// Start an unskip and interrupt it before any step runs
+	repo = reloadTestRepo(t)
+	steps, _, err := repo.planSkipSteps(false, "", repo.Worktrees, "config/a.yml")
+	if err != nil {
+		t.Fatalf("planSkipSteps failed: %v", err)
+	}
+	journal := &skipJournal{
+		Operation: "unskip config",
+		Steps:     steps,
+		Config:    skipConfigChange{RemovePattern: "config", Paths: []string{"config/a.yml", "config"}},
+	}
+	if err := repo.startSkipJournal(journal); err != nil {
+		t.Fatalf("startSkipJournal failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if _, err := repo.ResumeSkipJournal(); err != nil {
+		t.Fatalf("ResumeSkipJournal failed: %v", err)
+	}
+
+	repo = reloadTestRepo(t)
+	if patterns := repo.ListSkipPatterns(); len(patterns) != 0 {
+		t.Fatalf("expected the pattern to be forgotten, got %v", patterns)
+	}
+	if len(repo.Config.SkipStrategies) != 0 {
+		t.Fatalf("expected strategies to be cleared, got %v", repo.Config.SkipStrategies)
+	}
+	skipped, err := repo.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		t.Fatalf("failed to list skipped files: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Fatalf("expected nothing skipped after resume, got %v", skipped)
+	}
+}
@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// SparseProfileNames returns the sparse-checkout profiles defined in the config, sorted
+func (r *Repo) SparseProfileNames() []string {
+	if r.Config == nil {
+		return nil
+	}
+	var names []string
+	for name := range r.Config.SparseProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sparseDirs returns the directories of a profile, or of several joined with
+// commas, in the order given
+func (r *Repo) sparseDirs(profile string) ([]string, error) {
+	var dirs []string
+	for _, name := range strings.Split(profile, ",") {
+		var patterns []string
+		if r.Config != nil {
+			patterns = r.Config.SparseProfiles[name]
+		}
+		if len(patterns) == 0 {
+			return nil, fmt.Errorf("unknown sparse profile '%s' (define it under sparseProfiles in %s)", name, r.ConfigPath())
+		}
+		dirs = append(dirs, patterns...)
+	}
+	return dirs, nil
+}
+
+// applySparseProfile replaces a worktree's sparse-checkout patterns with a
+// profile's directories in cone mode
+func (r *Repo) applySparseProfile(wt *Worktree, profile string) error {
+	dirs, err := r.sparseDirs(profile)
+	if err != nil {
+		return err
+	}
+	args := append([]string{"sparse-checkout", "set", "--cone"}, dirs...)
+	if _, err := r.RunGitCommand(wt, args...); err != nil {
+		return fmt.Errorf("failed to set sparse-checkout: %w", err)
+	}
+	return r.allowSkippedFiles(wt)
+}
+
+// allowSkippedFiles stops git from unskipping files that are on disk in a
+// sparse worktree, which it otherwise does whenever it reads the index
+func (r *Repo) allowSkippedFiles(wt *Worktree) error {
+	if _, err := r.RunGitCommand(wt, "config", "--worktree", "sparse.expectFilesOutsideOfPatterns", "true"); err != nil {
+		return fmt.Errorf("failed to keep skipped files in sparse-checkout: %w", err)
+	}
+	return nil
+}
+
+// SetSparseProfile switches a worktree to a profile, or to a full checkout
+// if profile is empty
+func (r *Repo) SetSparseProfile(wt *Worktree, profile string) error {
+	err := r.keepSkippedFiles(wt, func() error {
+		if profile == "" {
+			if _, err := r.RunGitCommand(wt, "sparse-checkout", "disable"); err != nil {
+				return fmt.Errorf("failed to disable sparse-checkout: %w", err)
+			}
+			return nil
+		}
+		return r.applySparseProfile(wt, profile)
+	})
+	if err != nil {
+		return err
+	}
+
+	meta := wt.Meta
+	meta.Profile = profile
+	return r.SetWorktreeMeta(wt, meta)
+}
+
+// AddSparseProfile checks out a profile's directories alongside those a
+// worktree already has
+func (r *Repo) AddSparseProfile(wt *Worktree, profile string) error {
+	if wt.Meta.Profile == "" {
+		return fmt.Errorf("worktree '%s' has a full checkout (use 'wrk sparse set' to choose a profile)", wt.Name)
+	}
+	if r.HasSparseProfile(wt, profile) {
+		return fmt.Errorf("worktree '%s' already has profile '%s'", wt.Name, profile)
+	}
+
+	dirs, err := r.sparseDirs(profile)
+	if err != nil {
+		return err
+	}
+	err = r.keepSkippedFiles(wt, func() error {
+		args := append([]string{"sparse-checkout", "add"}, dirs...)
+		if _, err := r.RunGitCommand(wt, args...); err != nil {
+			return fmt.Errorf("failed to add to sparse-checkout: %w", err)
+		}
+		return r.allowSkippedFiles(wt)
+	})
+	if err != nil {
+		return err
+	}
+
+	meta := wt.Meta
+	meta.Profile += "," + profile
+	return r.SetWorktreeMeta(wt, meta)
+}
+
+// keepSkippedFiles runs change, which alters a worktree's sparse-checkout,
+// then skips the files that were skipped before it again. git sparse-checkout
+// rewrites the skip-worktree bit of every file, and wrk skip uses the same bit.
+func (r *Repo) keepSkippedFiles(wt *Worktree, change func() error) error {
+	skipped, err := r.getSkippedFilesInWorktree(wt)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+
+	// A file sparse-checkout removed is left out by it anyway
+	var files []string
+	for file := range skipped {
+		if _, err := os.Lstat(filepath.Join(wt.Path, file)); err == nil {
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Strings(files)
+	args := append([]string{"update-index", "--skip-worktree", "--"}, files...)
+	if _, err := r.RunGitCommand(wt, args...); err != nil {
+		return fmt.Errorf("failed to skip files again after changing sparse-checkout: %w", err)
+	}
+	return nil
+}
+
+// HasSparseProfile reports whether a profile is among a worktree's active ones
+func (r *Repo) HasSparseProfile(wt *Worktree, profile string) bool {
+	if wt.Meta.Profile == "" {
+		return false
+	}
+	for _, name := range strings.Split(wt.Meta.Profile, ",") {
+		if name == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// dropInheritedSparse gives a worktree a full checkout. git worktree add
+// copies sparse-checkout from the worktree it is run in, which would leave a
+// worktree created from a sparse one without most of its files.
+func (r *Repo) dropInheritedSparse(wt *Worktree) {
+	if !r.sparseEnabled(wt) {
+		return
+	}
+	if _, err := r.RunGitCommand(wt, "sparse-checkout", "disable"); err != nil {
+		color.Yellow("Warning: failed to disable inherited sparse-checkout: %v\n", err)
+	}
+}
+
+// sparseEnabled reports whether a worktree has sparse-checkout turned on
+func (r *Repo) sparseEnabled(wt *Worktree) bool {
+	output, err := r.RunGitCommand(wt, "config", "--worktree", "--get", "core.sparseCheckout")
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
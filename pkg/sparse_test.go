@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupSparseRepo creates a repository with two services and a profile for each
+func setupSparseRepo(t *testing.T) *Repo {
+	t.Helper()
+	repo := setupTestRepo(t)
+	for _, dir := range []string{"svc/api", "svc/web"} {
+		if err := os.MkdirAll(filepath.Join(repo.MainWorktree.Path, dir), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+		writeTestFile(t, filepath.Join(repo.MainWorktree.Path, dir, "main.go"), "package main\n")
+	}
+	runGit(t, repo.MainWorktree.Path, "add", ".")
+	runGit(t, repo.MainWorktree.Path, "commit", "-m", "services")
+
+	repo.Config = &Config{SparseProfiles: map[string][]string{
+		"api": {"svc/api"},
+		"web": {"svc/web"},
+	}}
+	return repo
+}
+
+func assertExists(t *testing.T, path string, want bool) {
+	t.Helper()
+	_, err := os.Stat(path)
+	if got := err == nil; got != want {
+		t.Fatalf("expected %s to exist: %v, got %v", path, want, got)
+	}
+}
+
+func TestCreateSparseBranch_ChecksOutProfile(t *testing.T) {
+	repo := setupSparseRepo(t)
+
+	if _, err := repo.CreateSparseBranch("feature", "feature", "missing"); err == nil {
+		t.Fatalf("expected CreateSparseBranch to reject an unknown profile")
+	}
+	if repo.BranchExists("feature") {
+		t.Fatalf("expected no branch to be created for an unknown profile")
+	}
+
+	wt, err := repo.CreateSparseBranch("feature", "feature", "api")
+	if err != nil {
+		t.Fatalf("CreateSparseBranch failed: %v", err)
+	}
+	assertExists(t, filepath.Join(wt.Path, "svc", "api", "main.go"), true)
+	assertExists(t, filepath.Join(wt.Path, "svc", "web", "main.go"), false)
+
+	repo = reloadTestRepo(t)
+	wt = repo.FindWorktreeByName("feature")
+	if wt.Meta.Profile != "api" {
+		t.Fatalf("expected profile api, got %q", wt.Meta.Profile)
+	}
+}
+
+func TestSparseProfile_SetAddAndFull(t *testing.T) {
+	repo := setupSparseRepo(t)
+	wt, err := repo.CreateSparseBranch("feature", "feature", "api")
+	if err != nil {
+		t.Fatalf("CreateSparseBranch failed: %v", err)
+	}
+	api := filepath.Join(wt.Path, "svc", "api", "main.go")
+	web := filepath.Join(wt.Path, "svc", "web", "main.go")
+
+	if err := repo.SetSparseProfile(wt, "web"); err != nil {
+		t.Fatalf("SetSparseProfile failed: %v", err)
+	}
+	assertExists(t, api, false)
+	assertExists(t, web, true)
+
+	if err := repo.AddSparseProfile(wt, "api"); err != nil {
+		t.Fatalf("AddSparseProfile failed: %v", err)
+	}
+	assertExists(t, api, true)
+	if wt.Meta.Profile != "web,api" || !repo.HasSparseProfile(wt, "api") {
+		t.Fatalf("expected profile web,api, got %q", wt.Meta.Profile)
+	}
+	if err := repo.AddSparseProfile(wt, "api"); err == nil {
+		t.Fatalf("expected adding an active profile to fail")
+	}
+
+	if err := repo.SetSparseProfile(wt, ""); err != nil {
+		t.Fatalf("SetSparseProfile failed: %v", err)
+	}
+	assertExists(t, api, true)
+	assertExists(t, web, true)
+	if wt.Meta.Profile != "" {
+		t.Fatalf("expected no profile, got %q", wt.Meta.Profile)
+	}
+}
+
+func TestCreateNewBranch_FromSparseWorktreeIsFull(t *testing.T) {
+	repo := setupSparseRepo(t)
+	wt, err := repo.CreateSparseBranch("sparse", "sparse", "api")
+	if err != nil {
+		t.Fatalf("CreateSparseBranch failed: %v", err)
+	}
+
+	// git worktree add copies sparse-checkout from the worktree it runs in
+	if err := os.Chdir(wt.Path); err != nil {
+		t.Fatalf("failed to enter worktree: %v", err)
+	}
+	full, err := repo.CreateNewBranch("full", "full")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	assertExists(t, filepath.Join(full.Path, "svc", "web", "main.go"), true)
+}
+
+func TestCreateSparseBranch_RollsBackOnFailure(t *testing.T) {
+	repo := setupSparseRepo(t)
+	// Cone mode only takes directories, so git rejects a glob
+	repo.Config.SparseProfiles["bad"] = []string{"svc/*"}
+
+	if _, err := repo.CreateSparseBranch("feature", "feature", "bad"); err == nil {
+		t.Fatalf("expected CreateSparseBranch to fail for a glob")
+	}
+	if repo.BranchExists("feature") {
+		t.Fatalf("expected the branch to be deleted")
+	}
+	assertExists(t, repo.GetWorktreePath("feature"), false)
+
+	if _, err := repo.CreateSparseBranch("feature", "feature", "api"); err != nil {
+		t.Fatalf("expected a retry to succeed, got %v", err)
+	}
+}
+
+func TestSparseProfile_KeepsSkippedFiles(t *testing.T) {
+	repo := setupSparseRepo(t)
+	if err := repo.SkipFile("README.md", ""); err != nil {
+		t.Fatalf("SkipFile failed: %v", err)
+	}
+	wt, err := repo.CreateSparseBranch("feature", "feature", "api")
+	if err != nil {
+		t.Fatalf("CreateSparseBranch failed: %v", err)
+	}
+
+	// git sparse-checkout rewrites the skip-worktree bit of every file
+	steps := []struct {
+		name   string
+		change func() error
+	}{
+		{"set", func() error { return repo.SetSparseProfile(wt, "web") }},
+		{"add", func() error { return repo.AddSparseProfile(wt, "api") }},
+		{"full", func() error { return repo.SetSparseProfile(wt, "") }},
+	}
+	for _, step := range steps {
+		if err := step.change(); err != nil {
+			t.Fatalf("%s failed: %v", step.name, err)
+		}
+		skipped, err := repo.getSkippedFilesInWorktree(wt)
+		if err != nil {
+			t.Fatalf("getSkippedFilesInWorktree failed: %v", err)
+		}
+		if !skipped["README.md"] {
+			t.Fatalf("expected README.md to stay skipped after %s, got %v", step.name, skipped)
+		}
+		if status := gitOutput(t, wt.Path, "status", "--porcelain"); status != "" {
+			t.Fatalf("expected a clean worktree after %s, got %q", step.name, status)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// SyncChange is a file that differs between the source and a target worktree
+type SyncChange struct {
+	File   string // Path relative to the worktree root
+	New    bool   // The target doesn't have the file yet
+	Edited bool   // The target's copy has edits that syncing would lose
+	Diff   string // Unified diff from the target's copy to the source's
+}
+
+// SyncTarget is a worktree together with the changes a sync would make to it
+type SyncTarget struct {
+	Worktree *Worktree
+	Changes  []SyncChange
+}
+
+// syncState records the blob last synced to each file, per target worktree
+// keyed by worktreeKey
+type syncState map[string]map[string]string
+
+func (r *Repo) syncStatePath() string {
+	return filepath.Join(r.WorktreesDir, ".sync.yml")
+}
+
+func (r *Repo) loadSyncState() (syncState, error) {
+	state := make(syncState)
+	data, err := os.ReadFile(r.syncStatePath())
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse sync state: %w", err)
+	}
+	return state, nil
+}
+
+func (r *Repo) saveSyncState(state syncState) error {
+	if err := r.EnsureWorktreesDir(); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync state: %w", err)
+	}
+	if err := os.WriteFile(r.syncStatePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write sync state: %w", err)
+	}
+	return nil
+}
+
+// PlanSync compares a file or directory in the current worktree with each
+// target and returns the files that would change. A target's copy counts as
+// edited unless it matches what was last synced to it or what it has committed.
+func (r *Repo) PlanSync(path string, targets []*Worktree) ([]SyncTarget, error) {
+	if r.CurrentWorktree == nil {
+		return nil, fmt.Errorf("not currently in a worktree")
+	}
+	return r.planSync(r.CurrentWorktree, path, targets)
+}
+
+// planSync compares a path in a source worktree with each target
+func (r *Repo) planSync(source *Worktree, path string, targets []*Worktree) ([]SyncTarget, error) {
+	files, err := listSyncFiles(source.Path, path)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := r.loadSyncState()
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []SyncTarget
+	for _, wt := range targets {
+		if wt == source {
+			continue
+		}
+
+		target := SyncTarget{Worktree: wt}
+		for _, file := range files {
+			change, changed, err := r.planSyncFile(source, wt, file, state[r.worktreeKey(wt)][file])
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				target.Changes = append(target.Changes, change)
+			}
+		}
+		plan = append(plan, target)
+	}
+
+	return plan, nil
+}
+
+// listSyncFiles lists the regular files and symlinks under a path in a worktree
+func listSyncFiles(root, path string) ([]string, error) {
+	fullPath := filepath.Join(root, path)
+	if _, err := os.Lstat(fullPath); err != nil {
+		return nil, fmt.Errorf("path does not exist: %s", path)
+	}
+
+	var files []string
+	err := filepath.Walk(fullPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// planSyncFile compares one file between the source and a target
+func (r *Repo) planSyncFile(source, target *Worktree, file, lastSynced string) (SyncChange, bool, error) {
+	srcPath := filepath.Join(source.Path, file)
+	dstPath := filepath.Join(target.Path, file)
+	change := SyncChange{File: file}
+
+	dstInfo, err := os.Stat(dstPath)
+	if os.IsNotExist(err) {
+		change.New = true
+	} else if err != nil {
+		return change, false, err
+	} else {
+		srcInfo, err := os.Stat(srcPath)
+		if err != nil {
+			return change, false, err
+		}
+		// A skip symlink or hardlink shared between the two is already in sync
+		if os.SameFile(srcInfo, dstInfo) {
+			return change, false, nil
+		}
+		same, err := sameContent(srcPath, dstPath)
+		if err != nil {
+			return change, false, err
+		}
+		if same {
+			return change, false, nil
+		}
+
+		blob, err := r.fileBlob(target, file)
+		if err != nil {
+			return change, false, err
+		}
+		change.Edited = blob != lastSynced && blob != r.committedBlob(target, file)
+	}
+
+	from := dstPath
+	if change.New {
+		from = os.DevNull
+	}
+	diff, err := r.diffPaths(from, srcPath)
+	if err != nil {
+		return change, false, err
+	}
+	change.Diff = diff
+
+	return change, true, nil
+}
+
+// fileBlob returns the blob id git would give a worktree file's current
+// content, after clean filters and line ending conversion and with the
+// repository's hash algorithm, so it compares equal to the committed blob
+func (r *Repo) fileBlob(wt *Worktree, file string) (string, error) {
+	args := []string{"-C", wt.Path, "hash-object", "--path=" + filepath.ToSlash(file), "--", file}
+	if GlobalFlags.Verbose {
+		fmt.Fprintf(os.Stderr, "Running: git %s\n", strings.Join(args, " "))
+	}
+	// Only stdout, since line ending conversion can warn on stderr
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", file, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// committedBlob returns the blob a file has at HEAD in a worktree, empty if untracked
+func (r *Repo) committedBlob(wt *Worktree, file string) string {
+	output, err := r.RunGitCommand(wt, "rev-parse", "--verify", "--quiet", "HEAD:"+filepath.ToSlash(file))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// diffPaths returns a unified diff between two paths, shown relative to the
+// directory containing the main worktree. Differences are not an error.
+func (r *Repo) diffPaths(from, to string, args ...string) (string, error) {
+	dir := filepath.Dir(r.MainWorktree.Path)
+	relFrom, relTo := from, to
+	if rel, err := filepath.Rel(dir, from); err == nil && from != os.DevNull {
+		relFrom = rel
+	}
+	if rel, err := filepath.Rel(dir, to); err == nil && to != os.DevNull {
+		relTo = rel
+	}
+
+	gitArgs := []string{"-C", dir, "diff", "--no-index"}
+	if !color.NoColor {
+		gitArgs = append(gitArgs, "--color=always")
+	}
+	gitArgs = append(gitArgs, args...)
+	gitArgs = append(gitArgs, "--", relFrom, relTo)
+
+	output, err := RunCommand("git", gitArgs...)
+	var exitErr *exec.ExitError
+	// Exit status 1 means the paths differ
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return "", fmt.Errorf("failed to diff %s and %s: %w\n%s", relFrom, relTo, err, output)
+	}
+	return string(output), nil
+}
+
+// ApplySync copies planned changes from the current worktree into each
+// target. Unless force is set, nothing is copied if any target has edits.
+func (r *Repo) ApplySync(plan []SyncTarget, force bool) error {
+	if !force {
+		var edited []string
+		for _, target := range plan {
+			for _, change := range target.Changes {
+				if change.Edited {
+					edited = append(edited, fmt.Sprintf("  %s: %s", target.Worktree.Name, change.File))
+				}
+			}
+		}
+		if len(edited) > 0 {
+			return fmt.Errorf("%d file(s) have local edits that would be overwritten (use --force to sync anyway):\n%s",
+				len(edited), strings.Join(edited, "\n"))
+		}
+	}
+
+	return r.applySync(r.CurrentWorktree, plan)
+}
+
+// applySync copies planned changes from a source worktree into each target
+// and records what was synced
+func (r *Repo) applySync(source *Worktree, plan []SyncTarget) error {
+	state, err := r.loadSyncState()
+	if err != nil {
+		return err
+	}
+
+	var errors []string
+	for _, target := range plan {
+		for _, change := range target.Changes {
+			srcPath := filepath.Join(source.Path, change.File)
+			dstPath := filepath.Join(target.Worktree.Path, change.File)
+
+			if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %s: %v", target.Worktree.Name, change.File, err))
+				continue
+			}
+			// Always-copy files are copied the same way as for new worktrees
+			opts := CopyOptions{Mode: r.alwaysCopyModeFor(change.File), Conflict: ConflictOverwrite}
+			if _, err := CopyPathWithOptions(srcPath, dstPath, opts); err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %s: %v", target.Worktree.Name, change.File, err))
+				continue
+			}
+
+			blob, err := r.fileBlob(target.Worktree, change.File)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("  %s: %s: %v", target.Worktree.Name, change.File, err))
+				continue
+			}
+			key := r.worktreeKey(target.Worktree)
+			if state[key] == nil {
+				state[key] = make(map[string]string)
+			}
+			state[key][change.File] = blob
+		}
+	}
+
+	if err := r.saveSyncState(state); err != nil {
+		errors = append(errors, fmt.Sprintf("  %v", err))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to sync %d file(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+
+	return nil
+}
+
+// PrintSyncPlan displays the diff for each target, returning the number of changed files
+func PrintSyncPlan(plan []SyncTarget) int {
+	total := 0
+	for _, target := range plan {
+		if len(target.Changes) == 0 {
+			fmt.Printf("%s: up to date\n", target.Worktree.Name)
+			continue
+		}
+
+		fmt.Printf("%s:\n", color.New(color.Bold).Sprint(target.Worktree.Name))
+		for _, change := range target.Changes {
+			switch {
+			case change.New:
+				fmt.Printf("  %s %s\n", color.GreenString("new"), change.File)
+			case change.Edited:
+				fmt.Printf("  %s %s\n", color.RedString("edited"), change.File)
+			default:
+				fmt.Printf("  %s %s\n", color.YellowString("changed"), change.File)
+			}
+		}
+		for _, change := range target.Changes {
+			fmt.Print(change.Diff)
+		}
+		total += len(target.Changes)
+	}
+
+	if total == 0 {
+		fmt.Println("Nothing to sync.")
+	}
+	return total
+}
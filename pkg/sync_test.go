@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSync_RefusesToClobberTargetEdits(t *testing.T) {
+	repo := setupTestRepo(t)
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByName("feature")
+	main := repo.MainWorktree.Path
+
+	sync := func(force bool) ([]SyncTarget, error) {
+		t.Helper()
+		plan, err := repo.PlanSync(".env", []*Worktree{feature})
+		if err != nil {
+			t.Fatalf("PlanSync failed: %v", err)
+		}
+		return plan, repo.ApplySync(plan, force)
+	}
+
+	read := func() string {
+		content, _ := os.ReadFile(filepath.Join(feature.Path, ".env"))
+		return string(content)
+	}
+
+	// A new file is pushed
+	writeTestFile(t, filepath.Join(main, ".env"), "A=1\n")
+	plan, err := sync(false)
+	if err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	if len(plan[0].Changes) != 1 || !plan[0].Changes[0].New || plan[0].Changes[0].Diff == "" {
+		t.Fatalf("expected a new file with a diff, got %+v", plan[0].Changes)
+	}
+	if read() != "A=1\n" {
+		t.Fatalf("expected .env synced, got %q", read())
+	}
+
+	// An unedited target is updated
+	writeTestFile(t, filepath.Join(main, ".env"), "A=2\n")
+	if _, err := sync(false); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	if read() != "A=2\n" {
+		t.Fatalf("expected .env updated, got %q", read())
+	}
+
+	// A target-side edit blocks the sync
+	writeTestFile(t, filepath.Join(feature.Path, ".env"), "A=local\n")
+	writeTestFile(t, filepath.Join(main, ".env"), "A=3\n")
+	plan, err = sync(false)
+	if err == nil {
+		t.Fatalf("expected sync to refuse target edits")
+	}
+	if !plan[0].Changes[0].Edited {
+		t.Fatalf("expected change to be marked edited")
+	}
+	if read() != "A=local\n" {
+		t.Fatalf("expected target edit to be kept, got %q", read())
+	}
+
+	if _, err := sync(true); err != nil {
+		t.Fatalf("forced sync failed: %v", err)
+	}
+	if read() != "A=3\n" {
+		t.Fatalf("expected forced sync to overwrite, got %q", read())
+	}
+}
+
+func TestSync_StateIsKeptPerWorktreePath(t *testing.T) {
+	repo := setupTestRepo(t)
+	for _, name := range []string{"feature/x", "bugfix/x"} {
+		if _, err := repo.CreateNewBranch(name, name); err != nil {
+			t.Fatalf("CreateNewBranch failed: %v", err)
+		}
+	}
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByBranch("feature/x")
+	bugfix := repo.FindWorktreeByBranch("bugfix/x")
+	main := repo.MainWorktree.Path
+
+	sync := func(target *Worktree, force bool) ([]SyncTarget, error) {
+		t.Helper()
+		plan, err := repo.PlanSync(".env", []*Worktree{target})
+		if err != nil {
+			t.Fatalf("PlanSync failed: %v", err)
+		}
+		return plan, repo.ApplySync(plan, force)
+	}
+
+	// Both worktrees are loaded as x, but each records its own synced blob
+	writeTestFile(t, filepath.Join(main, ".env"), "A=1\n")
+	if _, err := sync(feature, false); err != nil {
+		t.Fatalf("sync to feature/x failed: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, ".env"), "A=2\n")
+	if _, err := sync(bugfix, false); err != nil {
+		t.Fatalf("sync to bugfix/x failed: %v", err)
+	}
+
+	writeTestFile(t, filepath.Join(main, ".env"), "A=3\n")
+	plan, err := sync(feature, false)
+	if err != nil {
+		t.Fatalf("expected feature/x to be unedited, got %v", err)
+	}
+	if len(plan[0].Changes) != 1 || plan[0].Changes[0].Edited {
+		t.Fatalf("expected an unedited change, got %+v", plan[0].Changes)
+	}
+}
+
+func TestSync_CommittedFileWithLineEndingConversion(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+	runGit(t, main, "config", "core.autocrlf", "true")
+	writeTestFile(t, filepath.Join(main, "app.ini"), "A=1\n")
+	runGit(t, main, "add", "app.ini")
+	runGit(t, main, "commit", "-m", "ini")
+
+	// The feature checkout has CRLF line endings, but matches what's committed
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByName("feature")
+	writeTestFile(t, filepath.Join(feature.Path, "app.ini"), "A=1\r\n")
+
+	writeTestFile(t, filepath.Join(main, "app.ini"), "A=2\n")
+	plan, err := repo.PlanSync("app.ini", []*Worktree{feature})
+	if err != nil {
+		t.Fatalf("PlanSync failed: %v", err)
+	}
+	if len(plan) != 1 || len(plan[0].Changes) != 1 || plan[0].Changes[0].Edited {
+		t.Fatalf("expected an unedited change, got %+v", plan)
+	}
+}
@@ -0,0 +1,238 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// UpdateMethod determines how a worktree's branch is brought up to date
+type UpdateMethod string
+
+const (
+	UpdateFastForward UpdateMethod = "ff-only" // Only fast-forward, report diverged branches
+	UpdateRebase      UpdateMethod = "rebase"
+	UpdateMerge       UpdateMethod = "merge"
+)
+
+// UpdateStatus is the outcome of updating one worktree
+type UpdateStatus string
+
+const (
+	UpdateUpdated  UpdateStatus = "updated"
+	UpdateCurrent  UpdateStatus = "up to date"
+	UpdateSkipped  UpdateStatus = "skipped"
+	UpdateConflict UpdateStatus = "conflict"
+	UpdateFailed   UpdateStatus = "failed"
+)
+
+// UpdateOptions controls UpdateWorktrees
+type UpdateOptions struct {
+	Method    UpdateMethod // Defaults to fast-forward only
+	Autostash bool         // Stash local changes around the update instead of skipping dirty worktrees
+	NoFetch   bool         // Use the remote-tracking branches as they are
+}
+
+// UpdateResult reports what happened to one worktree
+type UpdateResult struct {
+	Worktree *Worktree
+	Status   UpdateStatus
+	Base     string // Ref the worktree was updated from
+	Detail   string
+}
+
+// UpdateWorktrees fetches once, then brings each worktree's branch up to date
+// with its base. A worktree that conflicts is restored to where it was and
+// the rest carry on.
+func (r *Repo) UpdateWorktrees(worktrees []*Worktree, opts UpdateOptions) ([]UpdateResult, error) {
+	if opts.Method == "" {
+		opts.Method = UpdateFastForward
+	}
+
+	if !opts.NoFetch {
+		if _, err := r.RunGitCommand(nil, "fetch", "--all", "--prune"); err != nil {
+			return nil, fmt.Errorf("failed to fetch: %w", err)
+		}
+	}
+
+	var results []UpdateResult
+	for _, wt := range worktrees {
+		results = append(results, r.updateWorktree(wt, opts))
+	}
+	return results, nil
+}
+
+// updateBase returns the ref a worktree's branch is updated from: the base
+// recorded when wrk created it, or else its upstream. The base comes first,
+// since a branch pushed with -u tracks its own remote copy and would never
+// pick up changes from the branch it was created from.
+func (r *Repo) updateBase(wt *Worktree) string {
+	// A base that is the branch itself, or a bare commit, can't move forward
+	if wt.Meta.Base != "" && wt.Meta.Base != wt.Branch && r.BranchExists(wt.Meta.Base) {
+		return wt.Meta.Base
+	}
+	output, err := r.RunGitCommand(wt, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// updateWorktree updates a single worktree
+func (r *Repo) updateWorktree(wt *Worktree, opts UpdateOptions) UpdateResult {
+	result := UpdateResult{Worktree: wt}
+
+	if wt.Branch == "" {
+		result.Status, result.Detail = UpdateSkipped, "detached HEAD"
+		return result
+	}
+
+	result.Base = r.updateBase(wt)
+	if result.Base == "" {
+		result.Status, result.Detail = UpdateSkipped, "no upstream or base branch"
+		return result
+	}
+
+	behind, ahead, err := r.aheadBehind(wt, result.Base)
+	if err != nil {
+		result.Status, result.Detail = UpdateFailed, err.Error()
+		return result
+	}
+	if behind == 0 {
+		result.Status = UpdateCurrent
+		return result
+	}
+
+	dirty, err := r.isDirty(wt)
+	if err != nil {
+		result.Status, result.Detail = UpdateFailed, err.Error()
+		return result
+	}
+	if dirty && !opts.Autostash {
+		result.Status, result.Detail = UpdateSkipped, "uncommitted changes (use --autostash)"
+		return result
+	}
+
+	var args []string
+	switch {
+	case ahead == 0 || opts.Method == UpdateFastForward:
+		if ahead > 0 {
+			result.Status = UpdateSkipped
+			result.Detail = fmt.Sprintf("diverged from %s by %d commit(s) (use --rebase or --merge)", result.Base, ahead)
+			return result
+		}
+		args = []string{"merge", "--ff-only"}
+	case opts.Method == UpdateRebase:
+		args = []string{"rebase"}
+	default:
+		args = []string{"merge", "--no-edit"}
+	}
+	if opts.Autostash {
+		args = append(args, "--autostash")
+	}
+	args = append(args, result.Base)
+
+	if output, err := r.RunGitCommand(wt, args...); err != nil {
+		// Only a merge or rebase left in progress stopped on conflicts
+		if operation := r.updateInProgress(wt); operation != "" {
+			result.Status, result.Detail = UpdateConflict, r.abortUpdate(wt, operation)
+		} else {
+			result.Status = UpdateFailed
+			result.Detail = fmt.Sprintf("%s failed: %s", args[0], strings.TrimSpace(string(output)))
+		}
+		return result
+	}
+
+	result.Status = UpdateUpdated
+	result.Detail = fmt.Sprintf("%d new commit(s) from %s", behind, result.Base)
+	return result
+}
+
+// aheadBehind counts the commits a worktree's HEAD is behind and ahead of a ref
+func (r *Repo) aheadBehind(wt *Worktree, ref string) (int, int, error) {
+	output, err := r.RunGitCommand(wt, "rev-list", "--left-right", "--count", ref+"...HEAD")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare with %s: %w", ref, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", output)
+	}
+	behind, _ := strconv.Atoi(fields[0])
+	ahead, _ := strconv.Atoi(fields[1])
+	return behind, ahead, nil
+}
+
+// isDirty reports whether a worktree has uncommitted changes to tracked files
+func (r *Repo) isDirty(wt *Worktree) (bool, error) {
+	output, err := r.RunGitCommand(wt, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, fmt.Errorf("failed to check status: %w", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// updateInProgress returns the merge or rebase a worktree has stopped in,
+// empty if neither
+func (r *Repo) updateInProgress(wt *Worktree) string {
+	output, err := r.RunGitCommand(wt, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return ""
+	}
+	gitDir := strings.TrimSpace(string(output))
+
+	for _, rebaseDir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(gitDir, rebaseDir)); err == nil {
+			return "rebase"
+		}
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "MERGE_HEAD")); err == nil {
+		return "merge"
+	}
+	return ""
+}
+
+// abortUpdate backs out of a failed rebase or merge, returning a description
+// of what happened
+func (r *Repo) abortUpdate(wt *Worktree, operation string) string {
+	if _, err := r.RunGitCommand(wt, operation, "--abort"); err != nil {
+		return fmt.Sprintf("%s stopped and could not be aborted, resolve it in %s", operation, wt.Path)
+	}
+	return fmt.Sprintf("%s stopped on conflicts, left unchanged", operation)
+}
+
+// PrintUpdateResults displays the outcome for each worktree, returning an
+// error if any conflicted or failed
+func PrintUpdateResults(results []UpdateResult) error {
+	problems := 0
+	for _, result := range results {
+		var status string
+		switch result.Status {
+		case UpdateUpdated:
+			status = color.GreenString(string(result.Status))
+		case UpdateCurrent:
+			status = string(result.Status)
+		case UpdateSkipped:
+			status = color.YellowString(string(result.Status))
+		default:
+			problems++
+			status = color.RedString(string(result.Status))
+		}
+
+		if result.Detail != "" {
+			fmt.Printf("%s: %s, %s\n", result.Worktree.Name, status, result.Detail)
+		} else {
+			fmt.Printf("%s: %s\n", result.Worktree.Name, status)
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("failed to update %d worktree(s)", problems)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdateWorktrees(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+	runGit(t, main, "push", "-u", "origin", "main")
+
+	// Worktrees tracking origin/main in different states
+	for _, name := range []string{"clean", "diverged", "conflict", "dirty"} {
+		runGit(t, main, "worktree", "add", "-b", name, filepath.Join(repo.WorktreesDir, name), "origin/main")
+	}
+	// local has no upstream but wrk recorded main as its base, nobase has neither
+	if _, err := repo.CreateNewBranch("local", "local"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	runGit(t, main, "worktree", "add", "-b", "nobase", filepath.Join(repo.WorktreesDir, "nobase"))
+
+	diverged := filepath.Join(repo.WorktreesDir, "diverged")
+	writeTestFile(t, filepath.Join(diverged, "other.txt"), "other\n")
+	runGit(t, diverged, "add", "other.txt")
+	runGit(t, diverged, "commit", "-m", "own work")
+
+	conflict := filepath.Join(repo.WorktreesDir, "conflict")
+	writeTestFile(t, filepath.Join(conflict, "README.md"), "mine\n")
+	runGit(t, conflict, "commit", "-am", "conflicting work")
+	conflictHead := strings.TrimSpace(gitOutput(t, conflict, "rev-parse", "HEAD"))
+
+	writeTestFile(t, filepath.Join(repo.WorktreesDir, "dirty", "README.md"), "uncommitted\n")
+
+	// Push an upstream change from another clone
+	otherDir := filepath.Join(t.TempDir(), "other")
+	runGit(t, main, "clone", "-b", "main", remoteDir, otherDir)
+	runGit(t, otherDir, "config", "user.email", "tests@example.com")
+	runGit(t, otherDir, "config", "user.name", "Tests")
+	writeTestFile(t, filepath.Join(otherDir, "README.md"), "upstream\n")
+	runGit(t, otherDir, "commit", "-am", "upstream change")
+	runGit(t, otherDir, "push", "origin", "main")
+
+	repo = reloadTestRepo(t)
+	all, err := repo.SelectWorktrees("")
+	if err != nil {
+		t.Fatalf("SelectWorktrees failed: %v", err)
+	}
+
+	statuses := func(results []UpdateResult) map[string]UpdateStatus {
+		byName := make(map[string]UpdateStatus)
+		for _, result := range results {
+			byName[result.Worktree.Name] = result.Status
+		}
+		return byName
+	}
+
+	results, err := repo.UpdateWorktrees(all, UpdateOptions{})
+	if err != nil {
+		t.Fatalf("UpdateWorktrees failed: %v", err)
+	}
+	got := statuses(results)
+	want := map[string]UpdateStatus{
+		"repo":     UpdateUpdated,
+		"clean":    UpdateUpdated,
+		"diverged": UpdateSkipped,
+		"conflict": UpdateSkipped,
+		"dirty":    UpdateSkipped,
+		"local":    UpdateUpdated,
+		"nobase":   UpdateSkipped,
+	}
+	for name, status := range want {
+		if got[name] != status {
+			t.Fatalf("fast-forward: expected %s to be %q, got %q (%+v)", name, status, got[name], results)
+		}
+	}
+
+	results, err = repo.UpdateWorktrees(all, UpdateOptions{Method: UpdateRebase, NoFetch: true})
+	if err != nil {
+		t.Fatalf("UpdateWorktrees failed: %v", err)
+	}
+	got = statuses(results)
+	if got["diverged"] != UpdateUpdated || got["conflict"] != UpdateConflict || got["clean"] != UpdateCurrent {
+		t.Fatalf("rebase: unexpected results %+v", results)
+	}
+	if head := strings.TrimSpace(gitOutput(t, conflict, "rev-parse", "HEAD")); head != conflictHead {
+		t.Fatalf("expected conflicting worktree to be left unchanged")
+	}
+	if status := gitOutput(t, conflict, "status", "--porcelain"); strings.TrimSpace(status) != "" {
+		t.Fatalf("expected rebase to be aborted cleanly, got status %q", status)
+	}
+	if err := PrintUpdateResults(results); err == nil {
+		t.Fatalf("expected an error for the conflicting worktree")
+	}
+}
+
+func TestUpdateWorktrees_FailureIsNotConflict(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	blocked := filepath.Join(repo.WorktreesDir, "blocked")
+	runGit(t, main, "worktree", "add", "-b", "blocked", blocked, "main")
+	runGit(t, blocked, "branch", "--set-upstream-to", "main")
+
+	// Upstream adds a file that exists untracked in the worktree, so the
+	// fast-forward refuses to start rather than stopping on conflicts
+	writeTestFile(t, filepath.Join(main, "new.txt"), "upstream\n")
+	runGit(t, main, "add", "new.txt")
+	runGit(t, main, "commit", "-m", "add new.txt")
+	writeTestFile(t, filepath.Join(blocked, "new.txt"), "local\n")
+
+	repo = reloadTestRepo(t)
+	wt := repo.FindWorktreeByName("blocked")
+	results, err := repo.UpdateWorktrees([]*Worktree{wt}, UpdateOptions{NoFetch: true})
+	if err != nil {
+		t.Fatalf("UpdateWorktrees failed: %v", err)
+	}
+	if results[0].Status != UpdateFailed || !strings.Contains(results[0].Detail, "new.txt") {
+		t.Fatalf("expected a failure naming new.txt, got %+v", results[0])
+	}
+}
+
+func TestUpdateWorktrees_PrefersRecordedBase(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+
+	remoteDir := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, main, "init", "--bare", remoteDir)
+	runGit(t, main, "remote", "add", "origin", remoteDir)
+
+	// The feature branch is pushed with -u, so its upstream is its own remote copy
+	wt, err := repo.CreateNewBranch("feature", "feature")
+	if err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	runGit(t, wt.Path, "push", "-q", "-u", "origin", "feature")
+
+	writeTestFile(t, filepath.Join(main, "new.txt"), "base change\n")
+	runGit(t, main, "add", "new.txt")
+	runGit(t, main, "commit", "-m", "base change")
+
+	repo = reloadTestRepo(t)
+	wt = repo.FindWorktreeByName("feature")
+	results, err := repo.UpdateWorktrees([]*Worktree{wt}, UpdateOptions{NoFetch: true})
+	if err != nil {
+		t.Fatalf("UpdateWorktrees failed: %v", err)
+	}
+	if results[0].Status != UpdateUpdated || results[0].Base != "main" {
+		t.Fatalf("expected feature to be updated from main, got %+v", results[0])
+	}
+	assertExists(t, filepath.Join(wt.Path, "new.txt"), true)
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return string(output)
+}
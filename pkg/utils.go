@@ -1,8 +1,8 @@
 package pkg
 
 import (
+	"bufio"
 	"fmt"
-	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +14,10 @@ import (
 
 const CD_DELIMITER = "__WORKTREE_CD__"
 
+// SkipExpiryPrompt is a command annotation that stops RepoCommand offering to
+// remove expired worktrees, for commands that handle expiry themselves
+const SkipExpiryPrompt = "wrk.skipExpiryPrompt"
+
 type GFlags struct {
 	Verbose bool
 	NoColor bool
@@ -34,13 +38,16 @@ func RepoCommand(fn func(*Repo, *cobra.Command, []string) error) func(*cobra.Com
 		if err != nil {
 			return err
 		}
+		if cmd.Annotations[SkipExpiryPrompt] == "" && IsInteractive() {
+			repo.OfferExpiredCleanup()
+		}
 		return fn(repo, cmd, args)
 	}
 }
 
 func RepoCompletion(fn func(*Repo, *cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		repo, err := LoadRepo()
+		repo, err := loadRepo()
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveNoFileComp
 		}
@@ -57,6 +64,21 @@ func RunCommand(name string, args ...string) ([]byte, error) {
 	return output, err
 }
 
+// Confirm asks a yes/no question on stdin, defaulting to no. The prompt ends
+// with a newline so it shows through the line-buffered wrk wrapper.
+func Confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]\n", prompt)
+
+	reader := bufio.NewReader(os.Stdin)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
 // IsTerminal checks if stdout is a terminal
 func IsTerminal() bool {
 	// Check if TERM is set (more reliable when output is captured)
@@ -73,43 +95,10 @@ func IsTerminal() bool {
 	return false
 }
 
-func CopyPath(src string, dst string) error {
-	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		relPath, err := filepath.Rel(src, path)
-		if err != nil {
-			return err
-		}
-
-		targetPath := filepath.Join(dst, relPath)
-
-		if info.IsDir() {
-			return os.MkdirAll(targetPath, info.Mode())
-		}
-
-		// It's a file
-		return copyFileWithMode(path, targetPath, info.Mode())
-	})
-}
-
-func copyFileWithMode(src, dst string, mode os.FileMode) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer in.Close()
-
-	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, mode)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	return err
+// IsInteractive checks if stdin is a terminal, so prompts can be answered
+func IsInteractive() bool {
+	fileInfo, err := os.Stdin.Stat()
+	return err == nil && (fileInfo.Mode()&os.ModeCharDevice) != 0
 }
 
 func GlobFilter(pattern string, candidates []string) []string {
@@ -0,0 +1,273 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// fileWatcher reports paths that change in watched directories. Watches are
+// not recursive, each directory is added separately.
+type fileWatcher interface {
+	Add(dir string) error
+	Events() <-chan string
+	Errors() <-chan error
+	Close() error
+}
+
+// WatchOptions controls WatchAlwaysCopy
+type WatchOptions struct {
+	Debounce time.Duration // Quiet period after a change before syncing, defaults to 300ms
+	Log      io.Writer     // Where sync activity is reported, defaults to stdout
+}
+
+// WatchAlwaysCopy mirrors changes to always-copy paths in the main worktree
+// into every other worktree until stop is closed. Files edited in a target
+// since they were last synced are left alone and reported as conflicts.
+// Activity is also appended to .sync.log in the worktrees directory.
+func (r *Repo) WatchAlwaysCopy(stop <-chan struct{}, opts WatchOptions) error {
+	if r.Config == nil || len(r.Config.Copy) == 0 {
+		return fmt.Errorf("no always-copy paths configured")
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = 300 * time.Millisecond
+	}
+	if opts.Log == nil {
+		opts.Log = os.Stdout
+	}
+
+	watcher, err := newFileWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	watched := 0
+	for _, path := range r.Config.Copy {
+		ok, err := r.watchCopyPath(watcher, path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			watched++
+		} else {
+			r.logWatch(opts.Log, "%s not found in %s, not watching it", path, r.MainWorktree.Name)
+		}
+	}
+
+	// Targets that match main now are treated as in sync, so edits in main
+	// aren't mistaken for conflicts
+	if err := r.recordSyncBaseline(r.Config.Copy); err != nil {
+		return err
+	}
+
+	r.logWatch(opts.Log, "watching %d always-copy path(s) in %s", watched, r.MainWorktree.Name)
+
+	pending := make(map[string]bool)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return nil
+
+		case err := <-watcher.Errors():
+			return err
+
+		case path := <-watcher.Events():
+			file, ok := r.watchedCopyFile(path)
+			if !ok {
+				continue
+			}
+			// New directories need their own watches
+			if info, err := os.Lstat(path); err == nil && info.IsDir() {
+				if err := addWatchTree(watcher, path); err != nil {
+					r.logWatch(opts.Log, "%v", err)
+				}
+			}
+			pending[file] = true
+			debounce = time.After(opts.Debounce)
+
+		case <-debounce:
+			files := make([]string, 0, len(pending))
+			for file := range pending {
+				files = append(files, file)
+			}
+			sort.Strings(files)
+			r.syncWatchedFiles(files, opts.Log)
+
+			pending = make(map[string]bool)
+			debounce = nil
+		}
+	}
+}
+
+// watchCopyPath watches an always-copy path in the main worktree. Files are
+// watched through their directory, since editors often replace them on save.
+// Reports false if neither the path nor its directory exists.
+func (r *Repo) watchCopyPath(watcher fileWatcher, path string) (bool, error) {
+	fullPath := filepath.Join(r.MainWorktree.Path, path)
+
+	info, err := os.Stat(fullPath)
+	if err == nil && info.IsDir() {
+		return true, addWatchTree(watcher, fullPath)
+	}
+
+	parent := filepath.Dir(fullPath)
+	if _, err := os.Stat(parent); err != nil {
+		return false, nil
+	}
+	return true, watcher.Add(parent)
+}
+
+// addWatchTree watches a directory and everything below it
+func addWatchTree(watcher fileWatcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchedCopyFile maps a changed path in the main worktree to its path
+// relative to the worktree, if it falls under an always-copy path
+func (r *Repo) watchedCopyFile(path string) (string, bool) {
+	rel, err := filepath.Rel(r.MainWorktree.Path, path)
+	if err != nil {
+		return "", false
+	}
+
+	for _, copyPath := range r.Config.Copy {
+		copyPath = filepath.Clean(copyPath)
+		if rel == copyPath || strings.HasPrefix(rel, copyPath+string(filepath.Separator)) {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// watchTargets returns the worktrees that always-copy changes are mirrored into
+func (r *Repo) watchTargets() []*Worktree {
+	var targets []*Worktree
+	for i := range r.Worktrees {
+		wt := &r.Worktrees[i]
+		if r.IsMainWorktree(wt) {
+			continue
+		}
+		// Skip worktrees removed since wrk started
+		if _, err := os.Stat(wt.Path); err != nil {
+			continue
+		}
+		targets = append(targets, wt)
+	}
+	return targets
+}
+
+// recordSyncBaseline marks files that are identical in main and a target as
+// synced, unless something was already recorded for them
+func (r *Repo) recordSyncBaseline(paths []string) error {
+	state, err := r.loadSyncState()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		files, err := listSyncFiles(r.MainWorktree.Path, path)
+		if err != nil {
+			// Missing paths are reported when watching starts
+			continue
+		}
+
+		for _, file := range files {
+			mainPath := filepath.Join(r.MainWorktree.Path, file)
+			for _, wt := range r.watchTargets() {
+				key := r.worktreeKey(wt)
+				if _, ok := state[key][file]; ok {
+					continue
+				}
+				same, err := sameContent(mainPath, filepath.Join(wt.Path, file))
+				if err != nil || !same {
+					continue
+				}
+				blob, err := r.fileBlob(wt, file)
+				if err != nil {
+					return err
+				}
+				if state[key] == nil {
+					state[key] = make(map[string]string)
+				}
+				state[key][file] = blob
+			}
+		}
+	}
+
+	return r.saveSyncState(state)
+}
+
+// syncWatchedFiles mirrors changed files from main into each target,
+// skipping files that were edited in the target
+func (r *Repo) syncWatchedFiles(files []string, log io.Writer) {
+	targets := r.watchTargets()
+
+	for _, file := range files {
+		if _, err := os.Lstat(filepath.Join(r.MainWorktree.Path, file)); os.IsNotExist(err) {
+			r.logWatch(log, "%s removed from %s, left in other worktrees", file, r.MainWorktree.Name)
+			continue
+		}
+
+		plan, err := r.planSync(r.MainWorktree, file, targets)
+		if err != nil {
+			r.logWatch(log, "failed to compare %s: %v", file, err)
+			continue
+		}
+
+		for _, target := range plan {
+			var changes []SyncChange
+			for _, change := range target.Changes {
+				if change.Edited {
+					r.logWatch(log, "conflict: %s was edited in %s, not synced", change.File, target.Worktree.Name)
+					continue
+				}
+				changes = append(changes, change)
+			}
+			if len(changes) == 0 {
+				continue
+			}
+
+			if err := r.applySync(r.MainWorktree, []SyncTarget{{Worktree: target.Worktree, Changes: changes}}); err != nil {
+				r.logWatch(log, "%v", err)
+				continue
+			}
+			for _, change := range changes {
+				r.logWatch(log, "synced %s -> %s", change.File, target.Worktree.Name)
+			}
+		}
+	}
+}
+
+// logWatch reports watch activity with a timestamp and appends it to the sync log
+func (r *Repo) logWatch(log io.Writer, format string, args ...any) {
+	line := fmt.Sprintf("[%s] %s\n", time.Now().Format("15:04:05"), fmt.Sprintf(format, args...))
+	fmt.Fprint(log, line)
+
+	f, err := os.OpenFile(r.syncLogPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s", time.Now().Format("2006-01-02"), line)
+}
+
+func (r *Repo) syncLogPath() string {
+	return filepath.Join(r.WorktreesDir, ".sync.log")
+}
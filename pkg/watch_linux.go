@@ -0,0 +1,140 @@
+//go:build linux
+
+package pkg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask covers files being written, created, replaced or removed
+const inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_CREATE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// inotifyWatcher is a fileWatcher backed by inotify
+type inotifyWatcher struct {
+	fd      int
+	mu      sync.Mutex
+	watches map[int]string // Watch descriptor to directory
+	events  chan string
+	errors  chan error
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newFileWatcher() (fileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start inotify: %w", err)
+	}
+
+	w := &inotifyWatcher{
+		fd:      fd,
+		watches: make(map[int]string),
+		events:  make(chan string, 256),
+		errors:  make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.readEvents()
+
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string) error {
+	wd, err := unix.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	w.mu.Lock()
+	w.watches[wd] = dir
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+func (w *inotifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+func (w *inotifyWatcher) Close() error {
+	close(w.done)
+	w.wg.Wait()
+	return unix.Close(w.fd)
+}
+
+// readEvents decodes inotify events into changed paths until the watcher is closed
+func (w *inotifyWatcher) readEvents() {
+	defer w.wg.Done()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+
+		// Poll with a timeout so Close is noticed
+		fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, 200)
+		if err == unix.EINTR || n == 0 {
+			continue
+		}
+		if err != nil {
+			w.errors <- fmt.Errorf("failed to poll inotify: %w", err)
+			return
+		}
+
+		n, err = unix.Read(w.fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			w.errors <- fmt.Errorf("failed to read inotify events: %w", err)
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			// struct inotify_event { int wd; uint32 mask, cookie, len; char name[]; }
+			wd := int(int32(binary.NativeEndian.Uint32(buf[offset:])))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[nameStart:nameStart+nameLen]), "\x00")
+			offset = nameStart + nameLen
+
+			w.mu.Lock()
+			dir, ok := w.watches[wd]
+			if mask&unix.IN_IGNORED != 0 {
+				delete(w.watches, wd)
+			}
+			w.mu.Unlock()
+
+			if !ok || mask&unix.IN_IGNORED != 0 {
+				continue
+			}
+
+			path := dir
+			if name != "" {
+				path = filepath.Join(dir, name)
+			}
+
+			select {
+			case w.events <- path:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
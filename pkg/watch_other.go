@@ -0,0 +1,9 @@
+//go:build !linux
+
+package pkg
+
+import "fmt"
+
+func newFileWatcher() (fileWatcher, error) {
+	return nil, fmt.Errorf("watch is only supported on Linux")
+}
@@ -0,0 +1,120 @@
+package pkg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchAlwaysCopy_MirrorsAndDetectsConflicts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("watch requires inotify")
+	}
+
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+	writeTestFile(t, filepath.Join(main, ".env"), "A=1\n")
+	if err := repo.AddAlwaysCopy(".env", ""); err != nil {
+		t.Fatalf("AddAlwaysCopy failed: %v", err)
+	}
+	for _, name := range []string{"feature", "bugfix"} {
+		if _, err := repo.CreateNewBranch(name, name); err != nil {
+			t.Fatalf("CreateNewBranch failed: %v", err)
+		}
+	}
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByName("feature")
+	bugfix := repo.FindWorktreeByName("bugfix")
+
+	// bugfix has its own edit before watching starts
+	writeTestFile(t, filepath.Join(bugfix.Path, ".env"), "A=local\n")
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.WatchAlwaysCopy(stop, WatchOptions{Debounce: 20 * time.Millisecond, Log: io.Discard})
+	}()
+
+	read := func(wt *Worktree) string {
+		content, _ := os.ReadFile(filepath.Join(wt.Path, ".env"))
+		return string(content)
+	}
+
+	waitFor := func(cond func() bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() && time.Now().Before(deadline) {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	// Wait for the watcher to start before changing main
+	waitFor(func() bool {
+		log, _ := os.ReadFile(repo.syncLogPath())
+		return strings.Contains(string(log), "watching")
+	})
+	writeTestFile(t, filepath.Join(main, ".env"), "A=2\n")
+	waitFor(func() bool {
+		log, _ := os.ReadFile(repo.syncLogPath())
+		return strings.Contains(string(log), "synced") && strings.Contains(string(log), "conflict")
+	})
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatalf("WatchAlwaysCopy failed: %v", err)
+	}
+
+	if read(feature) != "A=2\n" {
+		t.Fatalf("expected change mirrored to feature, got %q", read(feature))
+	}
+	if read(bugfix) != "A=local\n" {
+		t.Fatalf("expected bugfix edit to be kept, got %q", read(bugfix))
+	}
+
+	log, err := os.ReadFile(repo.syncLogPath())
+	if err != nil {
+		t.Fatalf("failed to read sync log: %v", err)
+	}
+	if !strings.Contains(string(log), "synced .env -> feature") || !strings.Contains(string(log), "conflict: .env was edited in bugfix") {
+		t.Fatalf("unexpected sync log:\n%s", log)
+	}
+}
+
+func TestSyncWatchedFiles_UsesAlwaysCopyMode(t *testing.T) {
+	repo := setupTestRepo(t)
+	main := repo.MainWorktree.Path
+	writeTestFile(t, filepath.Join(main, ".env"), "A=1\n")
+	if err := repo.AddAlwaysCopy(".env", CopyModeHardlink); err != nil {
+		t.Fatalf("AddAlwaysCopy failed: %v", err)
+	}
+	if _, err := repo.CreateNewBranch("feature", "feature"); err != nil {
+		t.Fatalf("CreateNewBranch failed: %v", err)
+	}
+	repo = reloadTestRepo(t)
+	feature := repo.FindWorktreeByName("feature")
+	if err := repo.recordSyncBaseline(repo.Config.Copy); err != nil {
+		t.Fatalf("recordSyncBaseline failed: %v", err)
+	}
+
+	// Saving by rename gives main a new file, which is linked again
+	if err := os.Remove(filepath.Join(main, ".env")); err != nil {
+		t.Fatalf("failed to remove .env: %v", err)
+	}
+	writeTestFile(t, filepath.Join(main, ".env"), "A=2\n")
+	repo.syncWatchedFiles([]string{".env"}, io.Discard)
+
+	info, err := os.Stat(filepath.Join(feature.Path, ".env"))
+	if err != nil {
+		t.Fatalf("expected .env in feature: %v", err)
+	}
+	mainInfo, err := os.Stat(filepath.Join(main, ".env"))
+	if err != nil {
+		t.Fatalf("failed to stat main .env: %v", err)
+	}
+	if !os.SameFile(info, mainInfo) {
+		t.Fatalf("expected .env to be hard-linked to main")
+	}
+}
@@ -0,0 +1,311 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFile is the file name searched for when no workspace is given
+const WorkspaceFile = "wrk-workspace.yml"
+
+// Workspace is a set of repositories that get worktrees for the same branch
+// together. Each repository keeps its worktrees in its own .{repo}.worktrees,
+// and a workspace directory links them side by side.
+type Workspace struct {
+	Name  string   `yaml:"name,omitempty"` // Defaults to the directory holding the file
+	Repos []string `yaml:"repos"`          // Paths relative to the file
+	Dir   string   `yaml:"dir,omitempty"`  // Parent of workspace directories, default .{name}.workspaces
+
+	path string // Path of the workspace file
+}
+
+// WorkspaceEntry describes one workspace directory
+type WorkspaceEntry struct {
+	Name    string   // Directory name, the branch with slashes replaced
+	Path    string   // Workspace directory
+	Repos   []string // Repositories linked into it
+	Missing []string // Repositories without a worktree in it
+}
+
+// FindWorkspace loads a workspace file. With no path it uses $WRK_WORKSPACE,
+// then searches from the current directory upwards for wrk-workspace.yml.
+func FindWorkspace(path string) (*Workspace, error) {
+	if path == "" {
+		path = os.Getenv("WRK_WORKSPACE")
+	}
+	if path == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+		for {
+			candidate := filepath.Join(dir, WorkspaceFile)
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return nil, fmt.Errorf("no %s found in this or any parent directory (use --file or WRK_WORKSPACE)", WorkspaceFile)
+			}
+			dir = parent
+		}
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %w", err)
+	}
+
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace: %w", err)
+	}
+	if len(ws.Repos) == 0 {
+		return nil, fmt.Errorf("workspace %s lists no repos", path)
+	}
+	ws.path = path
+
+	// Repositories are linked into workspace directories by base name
+	seen := make(map[string]string)
+	for _, repo := range ws.Repos {
+		name := filepath.Base(ws.resolve(repo))
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("workspace %s lists repos '%s' and '%s' with the same name '%s'", path, other, repo, name)
+		}
+		seen[name] = repo
+	}
+
+	if ws.Name == "" {
+		ws.Name = filepath.Base(ws.Home())
+	}
+	return &ws, nil
+}
+
+// resolve returns a path from the workspace file relative to its directory
+func (w *Workspace) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(w.Home(), path)
+}
+
+// Home returns the directory holding the workspace file
+func (w *Workspace) Home() string {
+	return filepath.Dir(w.path)
+}
+
+// Root returns the directory holding the workspace directories
+func (w *Workspace) Root() string {
+	if w.Dir != "" {
+		return w.resolve(w.Dir)
+	}
+	return filepath.Join(w.Home(), fmt.Sprintf(".%s.workspaces", w.Name))
+}
+
+// RepoPaths returns the absolute path of each member repository
+func (w *Workspace) RepoPaths() []string {
+	var paths []string
+	for _, repo := range w.Repos {
+		paths = append(paths, w.resolve(repo))
+	}
+	return paths
+}
+
+// EntryPath returns the workspace directory for a branch
+func (w *Workspace) EntryPath(branch string) string {
+	return filepath.Join(w.Root(), strings.ReplaceAll(branch, "/", "-"))
+}
+
+// LoadRepoAt loads the repository containing dir. The process moves into
+// dir, since repository commands run from the current directory.
+func LoadRepoAt(dir string) (*Repo, error) {
+	if err := os.Chdir(dir); err != nil {
+		return nil, fmt.Errorf("failed to enter %s: %w", dir, err)
+	}
+	return LoadRepo()
+}
+
+// forEachRepo loads each member repository in turn and calls fn, collecting
+// errors, then returns to the starting directory
+func (w *Workspace) forEachRepo(fn func(repo *Repo, link string) error) []string {
+	wd, _ := os.Getwd()
+	defer func() { _ = os.Chdir(wd) }()
+
+	var errors []string
+	for _, path := range w.RepoPaths() {
+		name := filepath.Base(path)
+		repo, err := LoadRepoAt(path)
+		if err == nil {
+			err = fn(repo, name)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("  %s: %v", name, err))
+		}
+	}
+	return errors
+}
+
+// New creates a worktree with a new branch in every member repository and
+// links them side by side in the branch's workspace directory. If any
+// repository fails, the worktrees already created are removed again.
+func (w *Workspace) New(branch string) (string, error) {
+	dir := w.EntryPath(branch)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	// Worktree paths created by this call, by link name
+	created := make(map[string]string)
+	errors := w.forEachRepo(func(repo *Repo, link string) error {
+		wt, err := repo.CreateNewBranch(branch, branch)
+		if err != nil {
+			return err
+		}
+		created[link] = wt.Path
+		return os.Symlink(wt.Path, filepath.Join(dir, link))
+	})
+
+	if len(errors) == 0 {
+		return dir, nil
+	}
+
+	err := fmt.Errorf("failed to create %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	if len(created) == 0 {
+		return dir, err
+	}
+	if rollbackErrors := w.removeCreated(dir, created); len(rollbackErrors) > 0 {
+		return dir, fmt.Errorf("%w\nthese worktrees could not be removed again:\n%s", err, strings.Join(rollbackErrors, "\n"))
+	}
+	// Leave a directory that held links before this call alone
+	_ = os.Remove(dir)
+	return dir, fmt.Errorf("%w\nthe worktrees created in other repositories were removed again", err)
+}
+
+// removeCreated removes the worktrees and branches New created, with their links
+func (w *Workspace) removeCreated(dir string, created map[string]string) []string {
+	return w.forEachRepo(func(repo *Repo, link string) error {
+		path, ok := created[link]
+		if !ok {
+			return nil
+		}
+		for i := range repo.Worktrees {
+			if repo.Worktrees[i].Path == path {
+				if err := repo.RemoveWorktree(&repo.Worktrees[i], true); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				break
+			}
+		}
+		if err := os.Remove(filepath.Join(dir, link)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// List returns the workspace directories, sorted by name
+func (w *Workspace) List() ([]WorkspaceEntry, error) {
+	dirEntries, err := os.ReadDir(w.Root())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspaces: %w", err)
+	}
+
+	var entries []WorkspaceEntry
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+		entry := WorkspaceEntry{Name: dirEntry.Name(), Path: filepath.Join(w.Root(), dirEntry.Name())}
+		for _, repo := range w.RepoPaths() {
+			name := filepath.Base(repo)
+			if _, err := os.Stat(filepath.Join(entry.Path, name)); err == nil {
+				entry.Repos = append(entry.Repos, name)
+			} else {
+				entry.Missing = append(entry.Missing, name)
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Find returns the workspace directory matching a name or glob
+func (w *Workspace) Find(pattern string) (*WorkspaceEntry, error) {
+	entries, err := w.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern = strings.ReplaceAll(pattern, "/", "-")
+	var matches []WorkspaceEntry
+	var names []string
+	for _, entry := range entries {
+		if matched, _ := filepath.Match(pattern, entry.Name); matched {
+			matches = append(matches, entry)
+			names = append(names, entry.Name)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no workspace found matching '%s'", pattern)
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, fmt.Errorf("pattern '%s' matches multiple workspaces:\n  %s", pattern, strings.Join(names, "\n  "))
+	}
+}
+
+// Remove removes each member repository's worktree linked from a workspace
+// directory, then the directory itself
+func (w *Workspace) Remove(entry *WorkspaceEntry, forceDeleteBranch bool) error {
+	errors := w.forEachRepo(func(repo *Repo, link string) error {
+		linkPath := filepath.Join(entry.Path, link)
+		target, err := os.Readlink(linkPath)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read link: %w", err)
+		}
+
+		var wt *Worktree
+		for i := range repo.Worktrees {
+			if repo.Worktrees[i].Path == target {
+				wt = &repo.Worktrees[i]
+				break
+			}
+		}
+		// Keep the link so the workspace still shows what is left
+		if wt == nil {
+			return fmt.Errorf("no worktree found at %s", target)
+		}
+		if err := repo.RemoveWorktree(wt, forceDeleteBranch); err != nil {
+			return err
+		}
+		return os.Remove(linkPath)
+	})
+
+	if len(errors) > 0 {
+		return fmt.Errorf("failed to remove %d worktree(s):\n%s", len(errors), strings.Join(errors, "\n"))
+	}
+	if err := os.Remove(entry.Path); err != nil {
+		return fmt.Errorf("failed to remove workspace directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initTestRepoAt creates a repository with one commit on main
+func initTestRepoAt(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create repo dir: %v", err)
+	}
+	runGit(t, dir, "init")
+	runGit(t, dir, "config", "user.email", "tests@example.com")
+	runGit(t, dir, "config", "user.name", "Tests")
+	writeTestFile(t, filepath.Join(dir, "README.md"), "test\n")
+	runGit(t, dir, "add", "README.md")
+	runGit(t, dir, "commit", "-m", "init")
+	runGit(t, dir, "branch", "-M", "main")
+}
+
+func TestWorkspace_NewListRemove(t *testing.T) {
+	home := t.TempDir()
+	for _, name := range []string{"backend", "frontend"} {
+		initTestRepoAt(t, filepath.Join(home, name))
+	}
+	writeTestFile(t, filepath.Join(home, WorkspaceFile), "name: shop\nrepos:\n  - backend\n  - frontend\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if err := os.Chdir(filepath.Join(home, "backend")); err != nil {
+		t.Fatalf("failed to chdir: %v", err)
+	}
+
+	ws, err := FindWorkspace("")
+	if err != nil {
+		t.Fatalf("FindWorkspace failed: %v", err)
+	}
+
+	dir, err := ws.New("feature/login")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if dir != filepath.Join(home, ".shop.workspaces", "feature-login") {
+		t.Fatalf("unexpected workspace directory %s", dir)
+	}
+	for _, name := range []string{"backend", "frontend"} {
+		want := filepath.Join(home, "."+name+".worktrees", "feature", "login")
+		if target, err := os.Readlink(filepath.Join(dir, name)); err != nil || target != want {
+			t.Fatalf("expected %s to link to %s, got %q, %v", name, want, target, err)
+		}
+	}
+
+	// A repo that already has the branch fails without stopping the others
+	if _, err := ws.New("feature/login"); err == nil {
+		t.Fatalf("expected New to fail for an existing branch")
+	}
+
+	entry, err := ws.Find("feature/*")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(entry.Repos) != 2 || len(entry.Missing) != 0 {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	if err := ws.Remove(entry, true); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected the workspace directory to be removed")
+	}
+	repo, err := LoadRepoAt(filepath.Join(home, "frontend"))
+	if err != nil {
+		t.Fatalf("LoadRepoAt failed: %v", err)
+	}
+	if len(repo.Worktrees) != 1 || repo.BranchExists("feature/login") {
+		t.Fatalf("expected the worktree and branch to be removed from frontend")
+	}
+}
+
+func TestWorkspace_NewRollsBackAndRemoveReportsStrayLinks(t *testing.T) {
+	home := t.TempDir()
+	for _, name := range []string{"backend", "frontend"} {
+		initTestRepoAt(t, filepath.Join(home, name))
+	}
+	writeTestFile(t, filepath.Join(home, WorkspaceFile), "name: shop\nrepos:\n  - backend\n  - frontend\n")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get cwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	ws, err := FindWorkspace(filepath.Join(home, WorkspaceFile))
+	if err != nil {
+		t.Fatalf("FindWorkspace failed: %v", err)
+	}
+
+	// frontend already has the branch, so the backend worktree is undone
+	runGit(t, filepath.Join(home, "frontend"), "branch", "taken")
+	if _, err := ws.New("taken"); err == nil {
+		t.Fatalf("expected New to fail")
+	}
+	repo, err := LoadRepoAt(filepath.Join(home, "backend"))
+	if err != nil {
+		t.Fatalf("LoadRepoAt failed: %v", err)
+	}
+	if len(repo.Worktrees) != 1 || repo.BranchExists("taken") {
+		t.Fatalf("expected the backend worktree and branch to be rolled back")
+	}
+	if _, err := os.Stat(ws.EntryPath("taken")); !os.IsNotExist(err) {
+		t.Fatalf("expected the empty workspace directory to be removed")
+	}
+
+	// A link to something that isn't a worktree of the repo isn't silently dropped
+	dir, err := ws.New("feature")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	stray := filepath.Join(dir, "frontend")
+	if err := os.Remove(stray); err != nil {
+		t.Fatalf("failed to remove link: %v", err)
+	}
+	if err := os.Symlink(t.TempDir(), stray); err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	entry, err := ws.Find("feature")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if err := ws.Remove(entry, true); err == nil {
+		t.Fatalf("expected Remove to report the stray link")
+	}
+	if _, err := os.Lstat(stray); err != nil {
+		t.Fatalf("expected the stray link to be kept: %v", err)
+	}
+}
+
+func TestFindWorkspace_RejectsDuplicateRepoNames(t *testing.T) {
+	home := t.TempDir()
+	path := filepath.Join(home, WorkspaceFile)
+	writeTestFile(t, path, "repos:\n  - a/api\n  - b/api\n")
+
+	_, err := FindWorkspace(path)
+	if err == nil || !strings.Contains(err.Error(), "same name 'api'") {
+		t.Fatalf("expected a duplicate name error, got %v", err)
+	}
+}
@@ -14,6 +14,7 @@ type Worktree struct {
 	Branch       string // Branch name
 	Name         string // Worktree name
 	RemoteBranch string // Remote name if created from remote branch, empty if local
+	Meta         WorktreeMeta
 }
 
 // FindWorktreeByBranch finds a worktree by branch name
@@ -90,6 +91,32 @@ func (r *Repo) FindWorktree(pattern string) (*Worktree, error) {
 	return nil, fmt.Errorf("pattern '%s' matches multiple worktrees:\n  %s", pattern, strings.Join(aliasMatches, "\n  "))
 }
 
+// SelectWorktrees returns all worktrees matching a glob pattern, in list
+// order. An empty pattern selects every worktree.
+func (r *Repo) SelectWorktrees(pattern string) ([]*Worktree, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	matches, _ := r.FindWorktreeGlob(pattern)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no worktree found matching '%s'", pattern)
+	}
+
+	matched := make(map[*Worktree]bool)
+	for _, wt := range matches {
+		matched[wt] = true
+	}
+
+	var selected []*Worktree
+	for i := range r.Worktrees {
+		if matched[&r.Worktrees[i]] {
+			selected = append(selected, &r.Worktrees[i])
+		}
+	}
+	return selected, nil
+}
+
 // AddExistingBranch creates a worktree for an existing local or remote branch
 func (r *Repo) AddExistingBranch(branch, name, remote string) (*Worktree, error) {
 	// Check if worktree already exists
@@ -113,10 +140,10 @@ func (r *Repo) AddExistingBranch(branch, name, remote string) (*Worktree, error)
 	var err error
 	remoteBranch := fmt.Sprintf("%s/%s", remote, branch)
 	remoteTracking := ""
-	if r.BranchExists(branch) {
+	if r.LocalBranchExists(branch) {
 		// Branch exists locally
 		_, err = r.RunGitCommand(nil, "worktree", "add", worktreePath, branch)
-	} else if r.BranchExists(remoteBranch) {
+	} else if remote != "" && r.RemoteBranchExists(remote, branch) {
 		// Branch exists on remote, create worktree with tracking
 		_, err = r.RunGitCommand(nil, "worktree", "add", "-b", branch, worktreePath, remoteBranch)
 		remoteTracking = remoteBranch
@@ -135,6 +162,12 @@ func (r *Repo) AddExistingBranch(branch, name, remote string) (*Worktree, error)
 		RemoteBranch: remoteTracking,
 	}
 
+	base := remoteTracking
+	if base == "" {
+		base = r.branchUpstream(branch)
+	}
+	r.recordCreation(wt, newCreationMeta(base))
+
 	r.applyPostCreateSetup(wt)
 
 	return wt, nil
@@ -142,6 +175,21 @@ func (r *Repo) AddExistingBranch(branch, name, remote string) (*Worktree, error)
 
 // CreateNewBranch creates a worktree with a new branch
 func (r *Repo) CreateNewBranch(branch, name string) (*Worktree, error) {
+	return r.createNewBranch(branch, name, "")
+}
+
+// CreateSparseBranch creates a worktree with a new branch that only checks
+// out the directories of a sparse-checkout profile
+func (r *Repo) CreateSparseBranch(branch, name, profile string) (*Worktree, error) {
+	if _, err := r.sparseDirs(profile); err != nil {
+		return nil, err
+	}
+	return r.createNewBranch(branch, name, profile)
+}
+
+// createNewBranch creates a worktree with a new branch, checking out only
+// the given sparse profile's directories if one is given
+func (r *Repo) createNewBranch(branch, name, profile string) (*Worktree, error) {
 	// Check if branch already exists
 	if r.BranchExists(branch) {
 		return nil, fmt.Errorf("branch '%s' already exists", branch)
@@ -160,8 +208,16 @@ func (r *Repo) CreateNewBranch(branch, name string) (*Worktree, error) {
 	// Get the path for the new worktree using the custom name
 	worktreePath := r.GetWorktreePath(name)
 
-	// Create the new worktree with a new branch
-	_, err := r.RunGitCommand(nil, "worktree", "add", "-b", branch, worktreePath)
+	// The new branch starts from whatever is checked out here
+	base := r.currentRef()
+
+	// Create the new worktree with a new branch, checking out later if sparse
+	args := []string{"worktree", "add"}
+	if profile != "" {
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, "-b", branch, worktreePath)
+	_, err := r.RunGitCommand(nil, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create worktree: %w", err)
 	}
@@ -172,6 +228,25 @@ func (r *Repo) CreateNewBranch(branch, name string) (*Worktree, error) {
 		Name:   name,
 	}
 
+	if profile != "" {
+		err := r.applySparseProfile(wt, profile)
+		if err == nil {
+			if _, checkoutErr := r.RunGitCommand(wt, "checkout"); checkoutErr != nil {
+				err = fmt.Errorf("failed to check out: %w", checkoutErr)
+			}
+		}
+		// Undo the worktree and branch so the command can be retried
+		if err != nil {
+			_, _ = r.RunGitCommand(nil, "worktree", "remove", "--force", worktreePath)
+			_, _ = r.RunGitCommand(nil, "branch", "-D", branch)
+			return nil, err
+		}
+	}
+
+	meta := newCreationMeta(base)
+	meta.Profile = profile
+	r.recordCreation(wt, meta)
+
 	r.applyPostCreateSetup(wt)
 
 	return wt, nil
@@ -179,6 +254,11 @@ func (r *Repo) CreateNewBranch(branch, name string) (*Worktree, error) {
 
 // applyPostCreateSetup applies all post-create operations to a worktree
 func (r *Repo) applyPostCreateSetup(wt *Worktree) {
+	// Worktrees copy sparse-checkout from the one they were created in
+	if wt.Meta.Profile == "" {
+		r.dropInheritedSparse(wt)
+	}
+
 	// Apply skip-worktree settings to the new worktree
 	if err := r.applySkipSettingsToWorktree(wt); err != nil {
 		// Log error but don't fail the worktree creation
@@ -186,7 +266,7 @@ func (r *Repo) applyPostCreateSetup(wt *Worktree) {
 	}
 
 	// Apply always-copy settings
-	if err := r.ApplyAlwaysCopy(wt); err != nil {
+	if err := r.ApplyAlwaysCopy(wt, ""); err != nil {
 		// Log error but don't fail the worktree creation
 		color.Yellow("Warning: failed to apply always-copy: %v\n", err)
 	}
@@ -211,11 +291,16 @@ func (r *Repo) RemoveWorktree(wt *Worktree, forceDeleteBranch bool) error {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
+	if err := r.forgetWorktreeMeta(wt); err != nil {
+		color.Yellow("Warning: failed to remove worktree metadata: %v\n", err)
+	}
+	r.unregisterWorktree(wt)
+
 	// Determine if we should delete the branch
 	shouldDeleteBranch := forceDeleteBranch || (r.Config != nil && r.Config.DeleteBranchWithWorktree)
 
-	// Delete the branch if requested
-	if shouldDeleteBranch {
+	// Delete the branch if requested, detached worktrees have none
+	if shouldDeleteBranch && wt.Branch != "" {
 		_, err := r.RunGitCommand(r.MainWorktree, "branch", "-D", wt.Branch)
 		if err != nil {
 			return fmt.Errorf("failed to force delete branch: %w", err)